@@ -1,55 +1,733 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 )
 
+// ValueType is the type of the value taken by a flag or positional argument
+type ValueType int
+
+// The zero value is TypeString so that specs only need to set the type of non-string values
+const (
+	TypeString ValueType = iota
+	TypeBool
+	TypeInt
+	TypeDuration
+)
+
+// placeholder returns the name used for the value in help text
+func (t ValueType) placeholder() string {
+	switch t {
+	case TypeInt:
+		return "<int>"
+	case TypeDuration:
+		return "<duration>"
+	case TypeBool:
+		return ""
+	default:
+		return "<string>"
+	}
+}
+
+// parse converts the raw value of a flag or argument to its type
+func (t ValueType) parse(raw string) (any, error) {
+	switch t {
+	case TypeBool:
+		return strconv.ParseBool(raw)
+	case TypeInt:
+		return strconv.Atoi(raw)
+	case TypeDuration:
+		return time.ParseDuration(raw)
+	default:
+		return raw, nil
+	}
+}
+
+// describe returns the description of a type used in error messages
+func (t ValueType) describe() string {
+	switch t {
+	case TypeBool:
+		return "a boolean"
+	case TypeInt:
+		return "an integer"
+	case TypeDuration:
+		return "a duration such as '30s' or '5m'"
+	default:
+		return "a string"
+	}
+}
+
+// FlagSpec describes a `--name` flag accepted by a command. Flags that aren't boolean take a value
+// given either as `--name value` or `--name=value`.
+type FlagSpec struct {
+	Name     string
+	Type     ValueType
+	Default  string
+	Usage    string
+	Complete CompleteFunc
+}
+
+// ArgSpec describes a positional argument accepted by a command. A variadic argument must be the
+// last argument and collects all of the remaining positional arguments.
+type ArgSpec struct {
+	Name     string
+	Type     ValueType
+	Required bool
+	Variadic bool
+	Default  string
+	Usage    string
+	Complete CompleteFunc
+}
+
+// CommandDef describes a command along with the arguments and flags that it takes. Hidden
+// commands aren't listed in help or offered as completions. Commands with RawArgs are given their
+// arguments as-is, without them being checked against the definition.
+type CommandDef struct {
+	Name        string
+	Summary     string
+	Description string
+	Args        []ArgSpec
+	Flags       []FlagSpec
+	Handler     CmdHandler
+	Hidden      bool
+	RawArgs     bool
+}
+
+// Command is a command parsed from the arguments passed to `gator`. Args only contains the
+// positional arguments. The typed values of the arguments and flags can be looked up by name.
 type Command struct {
-	Name string
-	Args []string
+	Name   string
+	Args   []string
+	values map[string]any
+	set    map[string]bool
+}
+
+// String returns the value of a string argument or flag
+func (c Command) String(name string) string {
+	value, _ := c.values[name].(string)
+	return value
+}
+
+// Bool returns the value of a boolean flag
+func (c Command) Bool(name string) bool {
+	value, _ := c.values[name].(bool)
+	return value
+}
+
+// Int returns the value of an integer argument or flag
+func (c Command) Int(name string) int {
+	value, _ := c.values[name].(int)
+	return value
+}
+
+// Duration returns the value of a duration argument or flag
+func (c Command) Duration(name string) time.Duration {
+	value, _ := c.values[name].(time.Duration)
+	return value
+}
+
+// IsSet reports whether an argument or flag was given by the user rather than left at its default
+func (c Command) IsSet(name string) bool {
+	return c.set[name]
 }
 
 type Commands struct {
-	List map[string]func(*State, Command) error
+	List  map[string]CommandDef
+	order []string
 }
 
 func NewCommands() Commands {
 	cmds := Commands{
-		List: make(map[string]func(*State, Command) error),
-	}
-	cmds.Register("login", HandlerLogin)
-	cmds.Register("register", HandlerRegister)
-	cmds.Register("reset", HandlerReset)
-	cmds.Register("users", HandlerUsers)
-	cmds.Register("agg", HandlerAgg)
-	cmds.Register("addfeed", middlewareLoggedIn(HandlerAddFeed))
-	cmds.Register("feeds", HandlerFeeds)
-	cmds.Register("follow", middlewareLoggedIn(HandlerFollow))
-	cmds.Register("following", middlewareLoggedIn(HandlerFollowing))
-	cmds.Register("unfollow", middlewareLoggedIn(HandlerUnfollow))
-	cmds.Register("browse", middlewareLoggedIn(HandlerBrowse))
+		List: make(map[string]CommandDef),
+	}
+	postIDArg := ArgSpec{Name: "post-id", Type: TypeInt, Required: true, Usage: "ID of the post, as shown by `browse`"}
+
+	cmds.Register(CommandDef{
+		Name:    "login",
+		Summary: "Log in as an existing user",
+		Args: []ArgSpec{
+			{Name: "username", Required: true, Usage: "Name of the user", Complete: completeUsernames},
+		},
+		Handler: HandlerLogin,
+	})
+	cmds.Register(CommandDef{
+		Name:    "register",
+		Summary: "Create a new user and log in as them",
+		Args: []ArgSpec{
+			{Name: "username", Required: true, Usage: "Name of the new user"},
+		},
+		Handler: HandlerRegister,
+	})
+	cmds.Register(CommandDef{
+		Name:        "reset",
+		Summary:     "Delete all users along with their feeds",
+		Description: "Deletes every user from the database, along with the feeds they added, and logs out the current user.",
+		Handler:     HandlerReset,
+	})
+	cmds.Register(CommandDef{
+		Name:    "users",
+		Summary: "List all users",
+		Flags:   []FlagSpec{outputFlag},
+		Handler: HandlerUsers,
+	})
+	cmds.Register(CommandDef{
+		Name:    "agg",
+		Summary: "Fetch feeds continuously",
+		Description: "Runs in the foreground and fetches every feed that is due to be fetched at the given " +
+			"interval. Stop it with Ctrl+C.",
+		Args: []ArgSpec{
+			{Name: "interval", Type: TypeDuration, Default: defaultAggInterval.String(), Usage: "How often to check for feeds that are due"},
+			{Name: "workers", Type: TypeInt, Default: strconv.Itoa(defaultAggWorkers), Usage: "Number of feeds to fetch concurrently"},
+		},
+		Handler: HandlerAgg,
+	})
+	cmds.Register(CommandDef{
+		Name:    "addfeed",
+		Summary: "Add a feed and follow it",
+		Description: "Adds a feed and follows it as the current user. If the URL points to a web page then " +
+			"the feeds advertised by the page are used instead.",
+		Args: []ArgSpec{
+			{Name: "name", Required: true, Usage: "Name of the feed"},
+			{Name: "url", Required: true, Usage: "URL of the feed or of a page that links to it"},
+		},
+		Handler: middlewareLoggedIn(HandlerAddFeed),
+	})
+	cmds.Register(CommandDef{
+		Name:    "feeds",
+		Summary: "List all feeds",
+		Flags: []FlagSpec{
+			{Name: "errors", Type: TypeBool, Usage: "Only list the feeds that are failing or disabled"},
+			outputFlag,
+		},
+		Handler: HandlerFeeds,
+	})
+	cmds.Register(CommandDef{
+		Name:        "enablefeed",
+		Summary:     "Re-enable a disabled feed",
+		Description: "Clears the errors of a failing or disabled feed so that `agg` fetches it again straight away.",
+		Args: []ArgSpec{
+			{Name: "url", Required: true, Usage: "URL of the feed", Complete: completeFeedURLs},
+		},
+		Handler: HandlerEnableFeed,
+	})
+	cmds.Register(CommandDef{
+		Name:    "editfeed",
+		Summary: "Rename a feed or change its URL",
+		Description: "Changes the name or URL of a feed. Only the user who added the feed can change it. " +
+			"Changing the URL resets the feed's fetch schedule and errors.",
+		Args: []ArgSpec{
+			{Name: "feed-url", Required: true, Usage: "Current URL of the feed", Complete: completeOwnedFeedURLs},
+		},
+		Flags: []FlagSpec{
+			{Name: "name", Usage: "New name of the feed"},
+			{Name: "url", Usage: "New URL of the feed"},
+		},
+		Handler: middlewareLoggedIn(HandlerEditFeed),
+	})
+	cmds.Register(CommandDef{
+		Name:    "rmfeed",
+		Summary: "Delete a feed along with its posts",
+		Description: "Deletes a feed along with all of its posts and unfollows it for every user. Posts that " +
+			"other users have starred are deleted too. Only the user who added the feed can delete it.",
+		Args: []ArgSpec{
+			{Name: "url", Required: true, Usage: "URL of the feed", Complete: completeOwnedFeedURLs},
+		},
+		Flags: []FlagSpec{
+			{Name: "yes", Type: TypeBool, Usage: "Don't ask for confirmation"},
+		},
+		Handler: middlewareLoggedIn(HandlerRmFeed),
+	})
+	cmds.Register(CommandDef{
+		Name:    "follow",
+		Summary: "Follow an existing feed",
+		Args: []ArgSpec{
+			{Name: "url", Required: true, Usage: "URL of the feed", Complete: completeFeedURLs},
+		},
+		Handler: middlewareLoggedIn(HandlerFollow),
+	})
+	cmds.Register(CommandDef{
+		Name:    "following",
+		Summary: "List the feeds that you follow",
+		Flags:   []FlagSpec{outputFlag},
+		Handler: middlewareLoggedIn(HandlerFollowing),
+	})
+	cmds.Register(CommandDef{
+		Name:    "unfollow",
+		Summary: "Stop following a feed",
+		Args: []ArgSpec{
+			{Name: "url", Required: true, Usage: "URL of the feed", Complete: completeFollowedFeedURLs},
+		},
+		Handler: middlewareLoggedIn(HandlerUnfollow),
+	})
+	cmds.Register(CommandDef{
+		Name:        "browse",
+		Summary:     "Show posts from the feeds that you follow",
+		Description: "Shows the unread posts from the feeds that you follow, newest first.",
+		Args: []ArgSpec{
+			{Name: "limit", Type: TypeInt, Default: "2", Usage: "Maximum number of posts to show"},
+		},
+		Flags: []FlagSpec{
+			{Name: "full", Type: TypeBool, Usage: "Show the full content of each post if the feed provides it"},
+			{Name: "all", Type: TypeBool, Usage: "Include posts that you've already read"},
+			{Name: "unread", Type: TypeBool, Usage: "Only show unread posts (the default)"},
+			{Name: "feed", Type: TypeString, Usage: "Only show posts from the feed with this name or URL", Complete: completeFollowedFeeds},
+			{Name: "since", Type: TypeString, Usage: "Only show posts published on or after this date"},
+			{Name: "until", Type: TypeString, Usage: "Only show posts published before this date"},
+			{Name: "order", Type: TypeString, Default: "desc", Usage: "Order posts by publication date, 'asc' or 'desc'"},
+			{Name: "page", Type: TypeInt, Usage: "Show this page of posts"},
+			{Name: "offset", Type: TypeInt, Usage: "Skip this many posts"},
+			{Name: "cursor", Type: TypeString, Usage: "Show the posts after this cursor, as printed by a previous run"},
+			outputFlag,
+		},
+		Handler: middlewareLoggedIn(HandlerBrowse),
+	})
+	cmds.Register(CommandDef{
+		Name:    "import",
+		Summary: "Add and follow the feeds in an OPML file",
+		Args: []ArgSpec{
+			{Name: "file", Required: true, Usage: "Path to the OPML file"},
+		},
+		Handler: middlewareLoggedIn(HandlerImport),
+	})
+	cmds.Register(CommandDef{
+		Name:    "export",
+		Summary: "Print the feeds that you follow",
+		Flags: []FlagSpec{
+			{Name: "format", Type: TypeString, Default: "opml", Usage: "Export format. Only 'opml' is supported."},
+		},
+		Handler: middlewareLoggedIn(HandlerExport),
+	})
+	cmds.Register(CommandDef{
+		Name:    "read",
+		Summary: "Mark a post as read",
+		Args:    []ArgSpec{postIDArg},
+		Handler: middlewareLoggedIn(HandlerRead),
+	})
+	cmds.Register(CommandDef{
+		Name:    "open",
+		Summary: "Open a post in your browser and mark it as read",
+		Description: "Opens a post in your browser and marks it as read. The browser is started with the " +
+			"'browser_command' setting from the config file, which defaults to '" + defaultBrowserCommand + "'.",
+		Args:    []ArgSpec{postIDArg},
+		Handler: middlewareLoggedIn(HandlerOpen),
+	})
+	cmds.Register(CommandDef{
+		Name:    "mark-all-read",
+		Summary: "Mark all posts as read",
+		Args: []ArgSpec{
+			{Name: "feed-url", Usage: "Only mark the posts from this feed as read", Complete: completeFollowedFeedURLs},
+		},
+		Handler: middlewareLoggedIn(HandlerMarkAllRead),
+	})
+	cmds.Register(CommandDef{
+		Name:    "star",
+		Summary: "Star a post",
+		Args:    []ArgSpec{postIDArg},
+		Handler: middlewareLoggedIn(HandlerStar),
+	})
+	cmds.Register(CommandDef{
+		Name:    "unstar",
+		Summary: "Remove a post from your starred posts",
+		Args:    []ArgSpec{postIDArg},
+		Handler: middlewareLoggedIn(HandlerUnstar),
+	})
+	cmds.Register(CommandDef{
+		Name:    "starred",
+		Summary: "List your starred posts",
+		Args: []ArgSpec{
+			{Name: "limit", Type: TypeInt, Default: "10", Usage: "Maximum number of posts to show"},
+		},
+		Flags:   []FlagSpec{outputFlag},
+		Handler: middlewareLoggedIn(HandlerStarred),
+	})
+	cmds.Register(CommandDef{
+		Name:    "search",
+		Summary: "Search the posts from the feeds that you follow",
+		Description: "Searches the title and content of the posts from the feeds that you follow. The query " +
+			"supports quoted phrases, \"or\" and excluding words with \"-\".",
+		Args: []ArgSpec{
+			{Name: "query", Required: true, Variadic: true, Usage: "Words to search for"},
+		},
+		Flags:   []FlagSpec{outputFlag},
+		Handler: middlewareLoggedIn(HandlerSearch),
+	})
+	cmds.Register(CommandDef{
+		Name:    "tui",
+		Summary: "Browse posts in a full-screen terminal interface",
+		Description: "Starts a full-screen interface with a list of the feeds that you follow, their posts and " +
+			"a reader for the selected post. Press 'q' to quit. The keys for the other actions are shown at " +
+			"the bottom of the screen.",
+		Handler: middlewareLoggedIn(HandlerTui),
+	})
+	cmds.Register(CommandDef{
+		Name:    "help",
+		Summary: "Show help for a command",
+		Args: []ArgSpec{
+			{Name: "command", Usage: "Name of the command", Complete: cmds.completeCommandNames},
+		},
+		Handler: cmds.handlerHelp,
+	})
+	cmds.Register(CommandDef{
+		Name:    "completion",
+		Summary: "Print a shell completion script",
+		Description: "Prints a script that sets up tab completion of commands, flags, feeds and users for " +
+			"the given shell. See the README for how to load it.",
+		Args: []ArgSpec{
+			{Name: "shell", Required: true, Usage: "One of 'bash', 'zsh' or 'fish'", Complete: completeShells},
+		},
+		Handler: HandlerCompletion,
+	})
+	cmds.Register(CommandDef{
+		Name:    "__complete",
+		Summary: "Print the completions for a partial command line",
+		Args: []ArgSpec{
+			{Name: "shell", Usage: "Shell that is completing the command line"},
+			{Name: "words", Variadic: true, Usage: "Words of the command line after 'gator'"},
+		},
+		Handler: cmds.handlerComplete,
+		Hidden:  true,
+		RawArgs: true,
+	})
 
 	return cmds
 }
 
-// Register registers a new handler function for a command name
-func (c *Commands) Register(name string, f func(*State, Command) error) {
-	_, ok := c.List[name]
+// Register registers a new command. Commands are listed in help in the order they're registered.
+func (c *Commands) Register(def CommandDef) {
+	_, ok := c.List[def.Name]
 	if !ok {
-		c.List[name] = f
+		c.List[def.Name] = def
+		c.order = append(c.order, def.Name)
 	}
 }
 
-// Run executes a given command with the provided state if it exists
-func (c *Commands) Run(s *State, cmd Command) error {
-	cmdL, ok := c.List[cmd.Name]
+// globalFlags are the flags that can be given before the name of the command, as in
+// `gator --output json feeds`. They're passed on to the command, which must take a flag with the
+// same name. The same flag given after the name of the command takes precedence.
+var globalFlags = []FlagSpec{outputFlag}
+
+// Run executes the command named by the first of the given arguments, after any global flags,
+// with the provided state if it exists. The raw arguments of the command are checked against its
+// definition before the handler is called.
+func (c *Commands) Run(s *State, args []string) error {
+	global, args, err := parseGlobalFlags(args)
+	if err != nil {
+		switch {
+		case errors.Is(err, errHelp):
+			c.PrintUsage(os.Stdout)
+			return nil
+		default:
+			return fmt.Errorf("%w\n\nRun 'gator help' to see the available commands and global flags.", err)
+		}
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("No command given. Run 'gator help' to see the available commands.")
+	}
+
+	cmdName, args := args[0], args[1:]
+	def, ok := c.List[cmdName]
 	if !ok {
-		return fmt.Errorf("Command '%s' does not exist", cmd.Name)
+		return fmt.Errorf("Command '%s' does not exist. Run 'gator help' to see the available commands.", cmdName)
+	}
+
+	cmd, err := def.parse(args)
+	if err == nil {
+		err = def.applyGlobalFlags(&cmd, global)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, errHelp):
+			def.printHelp(os.Stdout)
+			return nil
+		default:
+			return fmt.Errorf("%w\n\nUsage: %s\nRun 'gator help %s' for more information.", err, def.usage(), def.Name)
+		}
 	}
 
-	err := cmdL(s, cmd)
+	err = def.Handler(s, cmd)
 	if err != nil {
 		return fmt.Errorf("Error running command '%s': %w", cmd.Name, err)
 	}
 	return nil
 }
+
+// handlerHelp is the handler for the `help` subcommand. Without arguments it lists all commands,
+// otherwise it shows the help for the given command.
+func (c *Commands) handlerHelp(s *State, cmd Command) error {
+	if !cmd.IsSet("command") {
+		c.PrintUsage(os.Stdout)
+		return nil
+	}
+
+	def, ok := c.List[cmd.String("command")]
+	if !ok {
+		return fmt.Errorf("Command '%s' does not exist", cmd.String("command"))
+	}
+	def.printHelp(os.Stdout)
+	return nil
+}
+
+// PrintUsage prints the list of available commands
+func (c *Commands) PrintUsage(w io.Writer) {
+	fmt.Fprintln(w, "gator is a command line RSS feed aggregator.")
+	fmt.Fprintln(w, "\nUsage: gator [global flags] <command> [arguments]")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "\nCommands:")
+	for _, name := range c.order {
+		if c.List[name].Hidden {
+			continue
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", name, c.List[name].Summary)
+	}
+	fmt.Fprintln(tw, "\nGlobal flags:")
+	printFlags(tw, globalFlags)
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nRun 'gator help <command>' or 'gator <command> --help' for more information about a command.")
+}
+
+// errHelp is returned while parsing a command's arguments when the user asked for help
+var errHelp = errors.New("Help requested")
+
+// parseGlobalFlags parses the global flags at the start of the arguments. The raw values of the
+// flags are returned along with the remaining arguments, which start with the name of the command.
+func parseGlobalFlags(args []string) (map[string]string, []string, error) {
+	global := make(map[string]string)
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		if args[0] == "--help" {
+			return nil, nil, errHelp
+		}
+
+		name, rawValue, hasValue := strings.Cut(strings.TrimPrefix(args[0], "--"), "=")
+		flag, ok := CommandDef{Flags: globalFlags}.flag(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("Unknown global flag '--%s'", name)
+		}
+		if _, ok := global[name]; ok {
+			return nil, nil, fmt.Errorf("Flag '--%s' was given more than once", name)
+		}
+
+		args = args[1:]
+		switch {
+		case flag.Type == TypeBool && !hasValue:
+			rawValue = "true"
+		case !hasValue:
+			if len(args) == 0 {
+				return nil, nil, fmt.Errorf("Flag '--%s' requires a value", name)
+			}
+			rawValue, args = args[0], args[1:]
+		}
+		global[name] = rawValue
+	}
+
+	return global, args, nil
+}
+
+// applyGlobalFlags sets the flags of a command that were given as global flags, unless they were
+// also given after the name of the command
+func (def CommandDef) applyGlobalFlags(cmd *Command, global map[string]string) error {
+	for name, rawValue := range global {
+		flag, ok := def.flag(name)
+		if !ok {
+			return fmt.Errorf("Command does not take the global flag '--%s'", name)
+		}
+		if cmd.set[name] {
+			continue
+		}
+
+		value, err := flag.Type.parse(rawValue)
+		if err != nil {
+			return fmt.Errorf("Invalid value '%s' for flag '--%s'. Expected %s.", rawValue, name, flag.Type.describe())
+		}
+		cmd.values[name] = value
+		cmd.set[name] = true
+	}
+
+	return nil
+}
+
+// parse checks the raw arguments of a command against its definition and converts the values of
+// its arguments and flags to their types. Arguments after `--` are never treated as flags.
+func (def CommandDef) parse(rawArgs []string) (Command, error) {
+	cmd := Command{
+		Name:   def.Name,
+		Args:   []string{},
+		values: make(map[string]any),
+		set:    make(map[string]bool),
+	}
+	if def.RawArgs {
+		cmd.Args = rawArgs
+		return cmd, nil
+	}
+
+	flagsDone := false
+	for i := 0; i < len(rawArgs); i++ {
+		arg := rawArgs[i]
+		if flagsDone || !strings.HasPrefix(arg, "--") {
+			cmd.Args = append(cmd.Args, arg)
+			continue
+		}
+		if arg == "--" {
+			flagsDone = true
+			continue
+		}
+		if arg == "--help" {
+			return Command{}, errHelp
+		}
+
+		name, rawValue, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		flag, ok := def.flag(name)
+		if !ok {
+			return Command{}, fmt.Errorf("Unknown flag '--%s'", name)
+		}
+		if cmd.set[name] {
+			return Command{}, fmt.Errorf("Flag '--%s' was given more than once", name)
+		}
+
+		switch {
+		case flag.Type == TypeBool && !hasValue:
+			rawValue = "true"
+		case !hasValue:
+			if i+1 >= len(rawArgs) {
+				return Command{}, fmt.Errorf("Flag '--%s' requires a value", name)
+			}
+			i++
+			rawValue = rawArgs[i]
+		}
+
+		value, err := flag.Type.parse(rawValue)
+		if err != nil {
+			return Command{}, fmt.Errorf("Invalid value '%s' for flag '--%s'. Expected %s.", rawValue, name, flag.Type.describe())
+		}
+		cmd.values[name] = value
+		cmd.set[name] = true
+	}
+
+	for i, spec := range def.Args {
+		if i >= len(cmd.Args) {
+			if spec.Required {
+				return Command{}, fmt.Errorf("Missing argument <%s>", spec.Name)
+			}
+			continue
+		}
+
+		rawValue := cmd.Args[i]
+		if spec.Variadic {
+			rawValue = strings.Join(cmd.Args[i:], " ")
+		}
+		value, err := spec.Type.parse(rawValue)
+		if err != nil {
+			return Command{}, fmt.Errorf("Invalid value '%s' for argument <%s>. Expected %s.", rawValue, spec.Name, spec.Type.describe())
+		}
+		cmd.values[spec.Name] = value
+		cmd.set[spec.Name] = true
+	}
+	if len(cmd.Args) > len(def.Args) && (len(def.Args) == 0 || !def.Args[len(def.Args)-1].Variadic) {
+		switch len(def.Args) {
+		case 0:
+			return Command{}, fmt.Errorf("Command does not take any arguments")
+		default:
+			return Command{}, fmt.Errorf("Too many arguments. Expected at most %d but got %d.", len(def.Args), len(cmd.Args))
+		}
+	}
+
+	// Fill in the defaults of anything that wasn't given. The defaults are part of the command
+	// definitions so a default that can't be parsed is a bug.
+	for _, spec := range def.Args {
+		if !cmd.set[spec.Name] && spec.Default != "" {
+			cmd.values[spec.Name] = mustParse(spec.Type, spec.Default)
+		}
+	}
+	for _, flag := range def.Flags {
+		if !cmd.set[flag.Name] && flag.Default != "" {
+			cmd.values[flag.Name] = mustParse(flag.Type, flag.Default)
+		}
+	}
+
+	return cmd, nil
+}
+
+func mustParse(t ValueType, raw string) any {
+	value, err := t.parse(raw)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default value %q: %s", raw, err))
+	}
+	return value
+}
+
+func (def CommandDef) flag(name string) (FlagSpec, bool) {
+	for _, flag := range def.Flags {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+	return FlagSpec{}, false
+}
+
+// usage returns the one line summary of how to call the command
+func (def CommandDef) usage() string {
+	parts := []string{"gator", def.Name}
+	for _, spec := range def.Args {
+		name := spec.Name
+		if spec.Variadic {
+			name += "..."
+		}
+		if spec.Required {
+			parts = append(parts, fmt.Sprintf("<%s>", name))
+		} else {
+			parts = append(parts, fmt.Sprintf("[%s]", name))
+		}
+	}
+	if len(def.Flags) > 0 {
+		parts = append(parts, "[flags]")
+	}
+	return strings.Join(parts, " ")
+}
+
+// printHelp prints the full help text for the command
+func (def CommandDef) printHelp(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s\n\n", def.usage())
+	if def.Description != "" {
+		fmt.Fprintln(w, def.Description)
+	} else {
+		fmt.Fprintf(w, "%s.\n", def.Summary)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if len(def.Args) > 0 {
+		fmt.Fprintln(tw, "\nArguments:")
+		for _, spec := range def.Args {
+			fmt.Fprintf(tw, "  %s\t%s%s\n", spec.Name, spec.Usage, defaultSuffix(spec.Default))
+		}
+	}
+	fmt.Fprintln(tw, "\nFlags:")
+	printFlags(tw, def.Flags)
+	tw.Flush()
+}
+
+// printFlags prints the usage of a list of flags followed by `--help`
+func printFlags(w io.Writer, flags []FlagSpec) {
+	for _, flag := range flags {
+		name := "--" + flag.Name
+		if placeholder := flag.Type.placeholder(); placeholder != "" {
+			name += " " + placeholder
+		}
+		fmt.Fprintf(w, "  %s\t%s%s\n", name, flag.Usage, defaultSuffix(flag.Default))
+	}
+	fmt.Fprintf(w, "  --help\tShow this help\n")
+}
+
+func defaultSuffix(defaultValue string) string {
+	if defaultValue == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (default %s)", defaultValue)
+}
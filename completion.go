@@ -0,0 +1,423 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CompleteFunc returns the possible values of an argument or flag for shell completion
+type CompleteFunc func(s *State) ([]string, error)
+
+// Bash splits words on ":" and "=", which breaks URLs and `--flag=value`, and leaves quotes in the
+// words, so the script passes the command line as is and `__complete` splits it instead.
+const bashCompletionScript = `# bash completion for gator
+_gator() {
+    local completions
+    completions=$(gator __complete bash "${COMP_LINE:0:COMP_POINT}" 2>/dev/null) || return
+    COMPREPLY=()
+    [[ -n $completions ]] && mapfile -t COMPREPLY <<< "$completions"
+}
+complete -o default -F _gator gator
+`
+
+const zshCompletionScript = `#compdef gator
+# zsh completion for gator
+_gator() {
+    local -a completions
+    completions=(${(f)"$(gator __complete zsh "${(@Q)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)"})
+    if (( ${#completions} == 0 )); then
+        _files
+        return
+    fi
+    compadd -a completions
+}
+compdef _gator gator
+`
+
+const fishCompletionScript = `# fish completion for gator
+function __gator_complete
+    set -l tokens (commandline -opc)
+    set -l current (commandline -ct)
+    set -l completions (gator __complete fish $tokens[2..-1] "$current" 2>/dev/null)
+    or return
+    if test (count $completions) -eq 0
+        __fish_complete_path "$current"
+        return
+    end
+    printf '%s\n' $completions
+end
+complete -c gator -f -a '(__gator_complete)'
+`
+
+// completionScripts contains the completion script for each supported shell. The scripts ask
+// `gator __complete` for the candidates of the word under the cursor so that commands, flags and
+// values pulled from the database only need to be known in one place. If there aren't any
+// candidates then the shells fall back to completing file names. Zsh and fish quote candidates
+// that contain spaces or other special characters themselves while bash needs `__complete` to do
+// it.
+var completionScripts = map[string]string{
+	"bash": bashCompletionScript,
+	"zsh":  zshCompletionScript,
+	"fish": fishCompletionScript,
+}
+
+// HandlerCompletion is a handler for the `completion` subcommand. `completion` prints the
+// completion script for the given shell.
+func HandlerCompletion(s *State, cmd Command) error {
+	script, ok := completionScripts[cmd.String("shell")]
+	if !ok {
+		return fmt.Errorf("Unsupported shell '%s'. Supported shells: bash, zsh, fish", cmd.String("shell"))
+	}
+
+	fmt.Print(script)
+
+	return nil
+}
+
+// handlerComplete is the handler for the hidden `__complete` subcommand used by the completion
+// scripts. It takes the name of the shell followed by the words of the command line after
+// `gator`, the last of which is the word being completed as it was typed, and prints the
+// candidates for that word one per line. Bash passes the whole command line up to the cursor as
+// a single word instead.
+func (c *Commands) handlerComplete(s *State, cmd Command) error {
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	shell, args := cmd.Args[0], cmd.Args[1:]
+
+	var words []shellWord
+	switch {
+	case shell == "bash" && len(args) > 0:
+		words = splitWords(args[0])[1:]
+	case len(args) > 0:
+		for _, arg := range args[:len(args)-1] {
+			words = append(words, shellWord{value: arg})
+		}
+		current := splitWords(args[len(args)-1])
+		words = append(words, current[len(current)-1])
+	}
+	if len(words) == 0 {
+		words = []shellWord{{}}
+	}
+
+	current := words[len(words)-1]
+	previous := make([]string, 0, len(words)-1)
+	for _, word := range words[:len(words)-1] {
+		previous = append(previous, word.value)
+	}
+
+	candidates, prefix := c.completionCandidates(s, previous, current.value)
+	for _, candidate := range candidates {
+		candidate = prefix + candidate
+		if !strings.HasPrefix(candidate, current.value) {
+			continue
+		}
+		if shell == "bash" {
+			candidate = quoteForBash(candidate[current.replaceFrom:], current.quote)
+		}
+		fmt.Println(candidate)
+	}
+
+	return nil
+}
+
+// shellWord is a word of a command line with its quotes and backslashes removed
+type shellWord struct {
+	value string
+
+	// quote is the quote character that is still open at the end of the word, if any
+	quote rune
+
+	// replaceFrom is the position in value from which bash replaces the word with a completion.
+	// Bash starts a new word after an unquoted ":" or "=", and at the start of an open quote.
+	replaceFrom int
+}
+
+// splitWords splits a command line into words the way the shell would. The line may end in the
+// middle of a word, including inside of a quote. If the line is empty or ends with whitespace
+// then an empty word is added at the end since that's the word being completed.
+func splitWords(line string) []shellWord {
+	var words []shellWord
+	var word strings.Builder
+	var current shellWord
+	inWord := false
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			// Inside of double quotes a backslash only escapes characters that are special there
+			if current.quote == '"' && !strings.ContainsRune("$`\"\\", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case current.quote == '\'':
+			if r == '\'' {
+				current.quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case current.quote == '"':
+			switch r {
+			case '"':
+				current.quote = 0
+			case '\\':
+				escaped = true
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == '\'' || r == '"':
+			current.quote = r
+			current.replaceFrom = word.Len()
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				current.value = word.String()
+				words = append(words, current)
+				word.Reset()
+				current = shellWord{}
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+			if r == ':' || r == '=' {
+				current.replaceFrom = word.Len()
+			}
+		}
+	}
+
+	current.value = word.String()
+	return append(words, current)
+}
+
+// quoteForBash escapes a completion candidate so that bash inserts it as a single word. Inside of
+// quotes only the characters that are special there need to be escaped.
+func quoteForBash(candidate string, quote rune) string {
+	var special string
+	switch quote {
+	case '\'':
+		return strings.ReplaceAll(candidate, "'", `'\''`)
+	case '"':
+		special = "$`\"\\"
+	default:
+		special = " \t\n\\'\"$`;&|<>()[]{}*?!#~^"
+	}
+
+	var quoted strings.Builder
+	for _, r := range candidate {
+		if strings.ContainsRune(special, r) {
+			quoted.WriteRune('\\')
+		}
+		quoted.WriteRune(r)
+	}
+	return quoted.String()
+}
+
+// completionCandidates returns the possible values of the word being completed given the words
+// that come before it. Candidates for the value of a `--flag=value` word are returned without the
+// flag so the prefix that has to be added back is returned alongside them.
+func (c *Commands) completionCandidates(s *State, previous []string, current string) ([]string, string) {
+	// Skip over any global flags given before the name of the command
+	for len(previous) > 0 && strings.HasPrefix(previous[0], "--") {
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(previous[0], "--"), "=")
+		previous = previous[1:]
+
+		flag, ok := CommandDef{Flags: globalFlags}.flag(name)
+		if ok && flag.Type != TypeBool && !hasValue {
+			if len(previous) == 0 {
+				return complete(s, flag.Complete), ""
+			}
+			previous = previous[1:]
+		}
+	}
+
+	if len(previous) == 0 {
+		if strings.HasPrefix(current, "--") {
+			return completeFlags(s, globalFlags, current)
+		}
+		names, _ := c.completeCommandNames(s)
+		return names, ""
+	}
+
+	def, ok := c.List[previous[0]]
+	if !ok || def.Hidden {
+		return nil, ""
+	}
+
+	// Work out which positional argument is being completed and whether the previous word is a
+	// flag that's still waiting for its value
+	nArgs := 0
+	flagsDone := false
+	var pendingFlag *FlagSpec
+	for _, word := range previous[1:] {
+		switch {
+		case pendingFlag != nil:
+			pendingFlag = nil
+		case flagsDone || !strings.HasPrefix(word, "--"):
+			nArgs++
+		case word == "--":
+			flagsDone = true
+		case !strings.Contains(word, "="):
+			flag, ok := def.flag(strings.TrimPrefix(word, "--"))
+			if ok && flag.Type != TypeBool {
+				pendingFlag = &flag
+			}
+		}
+	}
+
+	if pendingFlag != nil {
+		return complete(s, pendingFlag.Complete), ""
+	}
+
+	if !flagsDone && strings.HasPrefix(current, "--") {
+		return completeFlags(s, def.Flags, current)
+	}
+
+	if len(def.Args) == 0 {
+		return nil, ""
+	}
+	if nArgs >= len(def.Args) {
+		if !def.Args[len(def.Args)-1].Variadic {
+			return nil, ""
+		}
+		nArgs = len(def.Args) - 1
+	}
+	return complete(s, def.Args[nArgs].Complete), ""
+}
+
+// completeFlags returns the candidates for a word starting with "--". This is either the name of
+// one of the flags or, for `--flag=value` words, the value of the flag. Values are returned
+// without the flag so the prefix that has to be added back is returned alongside them.
+func completeFlags(s *State, flags []FlagSpec, current string) ([]string, string) {
+	name, _, hasValue := strings.Cut(strings.TrimPrefix(current, "--"), "=")
+	if hasValue {
+		flag, ok := CommandDef{Flags: flags}.flag(name)
+		if !ok {
+			return nil, ""
+		}
+		return complete(s, flag.Complete), "--" + name + "="
+	}
+
+	names := []string{"--help"}
+	for _, flag := range flags {
+		names = append(names, "--"+flag.Name)
+	}
+	return names, ""
+}
+
+// complete calls the completion function of an argument or flag if it has one. Errors are ignored
+// since there's nowhere to show them while the user is typing.
+func complete(s *State, f CompleteFunc) []string {
+	if f == nil {
+		return nil
+	}
+	candidates, err := f(s)
+	if err != nil {
+		return nil
+	}
+	return candidates
+}
+
+func (c *Commands) completeCommandNames(s *State) ([]string, error) {
+	var names []string
+	for _, name := range c.order {
+		if !c.List[name].Hidden {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func completeShells(s *State) ([]string, error) {
+	return []string{"bash", "zsh", "fish"}, nil
+}
+
+func completeUsernames(s *State) ([]string, error) {
+	users, err := s.DB.GetUsers(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names, nil
+}
+
+func completeFeedURLs(s *State) ([]string, error) {
+	feeds, err := s.DB.GetFeeds(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(feeds))
+	for _, feed := range feeds {
+		urls = append(urls, feed.Url)
+	}
+	return urls, nil
+}
+
+// completeOwnedFeedURLs returns the URLs of the feeds that were added by the current user
+func completeOwnedFeedURLs(s *State) ([]string, error) {
+	user, err := s.DB.GetUserByName(context.Background(), s.Config.CurrentUserName)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := s.DB.GetFeeds(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, feed := range feeds {
+		if feed.UserID == user.ID {
+			urls = append(urls, feed.Url)
+		}
+	}
+	return urls, nil
+}
+
+// completeFollowedFeeds returns the names and URLs of the feeds that the current user follows
+func completeFollowedFeeds(s *State) ([]string, error) {
+	user, err := s.DB.GetUserByName(context.Background(), s.Config.CurrentUserName)
+	if err != nil {
+		return nil, err
+	}
+
+	feedFollows, err := s.DB.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]string, 0, 2*len(feedFollows))
+	for _, feedFollow := range feedFollows {
+		candidates = append(candidates, feedFollow.FeedName, feedFollow.FeedUrl)
+	}
+	return candidates, nil
+}
+
+// completeFollowedFeedURLs returns the URLs of the feeds that the current user follows
+func completeFollowedFeedURLs(s *State) ([]string, error) {
+	user, err := s.DB.GetUserByName(context.Background(), s.Config.CurrentUserName)
+	if err != nil {
+		return nil, err
+	}
+
+	feedFollows, err := s.DB.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(feedFollows))
+	for _, feedFollow := range feedFollows {
+		urls = append(urls, feedFollow.FeedUrl)
+	}
+	return urls, nil
+}
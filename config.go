@@ -5,14 +5,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const configFileName = ".gatorconfig.json"
 
+// defaultBrowserCommand is used to open links when `browser_command` isn't set in the config
+const defaultBrowserCommand = "xdg-open"
+
 // Config contains the configuration settings for the gator CLI
 type Config struct {
 	DBUrl           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name,omitempty"`
+
+	// BrowserCommand is the command used to open links. The URL is passed as the last argument.
+	BrowserCommand string `json:"browser_command,omitempty"`
 }
 
 func getConfigFilePath() (string, error) {
@@ -51,6 +58,14 @@ func ReadConfig() (*Config, error) {
 	return &config, nil
 }
 
+// Browser returns the command used to open links, falling back to the default if it isn't set
+func (cfg *Config) Browser() string {
+	if strings.TrimSpace(cfg.BrowserCommand) == "" {
+		return defaultBrowserCommand
+	}
+	return cfg.BrowserCommand
+}
+
 // SetUser writes the config file to the `gatorconfig.json` file, the default location of which is in
 // the home directory.
 func (cfg *Config) SetUser(current_user string) error {
@@ -6,29 +6,35 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TheSeaGiraffe/gator/internal/database"
+	"github.com/TheSeaGiraffe/gator/internal/htmltext"
+	"github.com/TheSeaGiraffe/gator/internal/opml"
+	"github.com/TheSeaGiraffe/gator/internal/rss"
 	"github.com/google/uuid"
 )
 
-var defaultAggInterval = time.Minute * 5
+var (
+	defaultAggInterval = time.Minute * 5
+	defaultAggWorkers  = 1
+	defaultSearchLimit = 10
+
+	// Width that the text of posts is wrapped to
+	postTextWidth = 80
+)
 
 // HandlerLogin is a handler for the `login` subcommand. `login` is used to set the current user
 // to the specified user.
 func HandlerLogin(s *State, cmd Command) error {
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("No username provided")
-	} else if len(cmd.Args) > 1 {
-		return fmt.Errorf("Username must be a single string")
-	}
-
-	user, err := s.DB.GetUserByName(context.Background(), cmd.Args[0])
+	user, err := s.DB.GetUserByName(context.Background(), cmd.String("username"))
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return fmt.Errorf("User '%s' does not exist", cmd.Args[0])
+			return fmt.Errorf("User '%s' does not exist", cmd.String("username"))
 		default:
 			return err
 		}
@@ -47,26 +53,19 @@ func HandlerLogin(s *State, cmd Command) error {
 // HandlerRegister is a handler for the `register` subcommand. `register` adds the current user
 // to the database.
 func HandlerRegister(s *State, cmd Command) error {
-	// Check that a username was passed in the args
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("No username provided")
-	} else if len(cmd.Args) > 1 {
-		return fmt.Errorf("Username must be a single string")
-	}
-
 	// Create a new user in the database
 	userData := database.CreateUserParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-		Name:      cmd.Args[0],
+		Name:      cmd.String("username"),
 	}
 
 	user, err := s.DB.CreateUser(context.Background(), userData)
 	if err != nil {
 		switch {
 		case err.Error() == `pq: duplicate key value violates unique constraint "users_name_key"`:
-			return fmt.Errorf("User '%s' already exists", cmd.Args[0])
+			return fmt.Errorf("User '%s' already exists", cmd.String("username"))
 		default:
 			return err
 		}
@@ -88,11 +87,6 @@ func HandlerRegister(s *State, cmd Command) error {
 }
 
 func HandlerReset(s *State, cmd Command) error {
-	// Validate args
-	if len(cmd.Args) > 0 {
-		return fmt.Errorf("Command does not take any arguments")
-	}
-
 	// Delete all users in DB
 	err := s.DB.DeleteUsers(context.Background())
 	if err != nil {
@@ -112,9 +106,9 @@ func HandlerReset(s *State, cmd Command) error {
 }
 
 func HandlerUsers(s *State, cmd Command) error {
-	// Validate user args
-	if len(cmd.Args) > 0 {
-		return fmt.Errorf("Command does not take any arguments")
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
 	}
 
 	// Get users from DB. Don't forget to validate slice.
@@ -123,6 +117,14 @@ func HandlerUsers(s *State, cmd Command) error {
 		return err
 	}
 
+	if format != "text" {
+		records := make([]Record, 0, len(users))
+		for _, user := range users {
+			records = append(records, userRecord(user, user.Name == s.Config.CurrentUserName))
+		}
+		return renderRecords(os.Stdout, format, fieldNames(userRecord(database.User{}, false)), records)
+	}
+
 	if len(users) == 0 {
 		fmt.Println("Database currently does not contain any users.")
 		return nil
@@ -142,26 +144,21 @@ func HandlerUsers(s *State, cmd Command) error {
 }
 
 func HandlerAgg(s *State, cmd Command) error {
-	// Validate user args
-	var tickInterval time.Duration
-	var err error
-	if len(cmd.Args) > 1 {
-		return fmt.Errorf("Too may arguments. `agg` takes a time duration string.")
-	} else if len(cmd.Args) == 0 {
-		tickInterval = defaultAggInterval
-	} else {
-		tickInterval, err = time.ParseDuration(cmd.Args[0])
-		if err != nil {
-			return fmt.Errorf("Could not parse time duration string.")
-		}
+	tickInterval := cmd.Duration("interval")
+	workers := cmd.Int("workers")
+	if tickInterval <= 0 {
+		return fmt.Errorf("The interval must be a positive duration.")
+	}
+	if workers < 1 {
+		return fmt.Errorf("The number of workers must be a positive integer.")
 	}
 
-	// Get new feed after the specified tickInterval
-	// Since we're using `agg` to print feeds I think we should just log to a file in the event of an error
-	// For now I'll just break out of the loop.
+	// Fetch all due feeds after the specified tickInterval
+	// Feeds that fail to be fetched are tracked in the DB so we only break out of the loop if
+	// something goes wrong with the DB itself.
 	ticker := time.NewTicker(tickInterval)
 	for ; ; <-ticker.C {
-		err = scrapeFeeds(s)
+		err := scrapeFeeds(s, workers)
 		if err != nil {
 			ticker.Stop()
 			return fmt.Errorf("Error fetching feed: %w", err)
@@ -170,22 +167,22 @@ func HandlerAgg(s *State, cmd Command) error {
 }
 
 func HandlerAddFeed(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) < 2 {
-		return fmt.Errorf("Missing arguments. `addfeed` takes the name of the RSS feed and its URL.")
-	} else if len(cmd.Args) > 2 {
-		return fmt.Errorf("Too many arguments. `addfeed` takes the name of the RSS feed and its URL.")
+	_, err := url.ParseRequestURI(cmd.String("url"))
+	if err != nil {
+		return fmt.Errorf("Invalid URL")
 	}
 
-	_, err := url.ParseRequestURI(cmd.Args[1])
+	// The URL may point to a web page instead of the feed itself
+	feedURL, err := discoverFeedURL(cmd.String("url"))
 	if err != nil {
-		return fmt.Errorf("Invalid URL")
+		return err
 	}
 
 	rssFeedParams := database.CreateFeedParams{
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-		Name:      cmd.Args[0],
-		Url:       cmd.Args[1],
+		Name:      cmd.String("name"),
+		Url:       feedURL,
 		UserID:    user.ID,
 	}
 	rssFeed, err := s.DB.CreateFeed(context.Background(), rssFeedParams)
@@ -215,16 +212,132 @@ func HandlerAddFeed(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
+// HandlerEditFeed is a handler for the `editfeed` subcommand. `editfeed` changes the name or URL of
+// a feed added by the current user.
+func HandlerEditFeed(s *State, cmd Command, user database.User) error {
+	if !cmd.IsSet("name") && !cmd.IsSet("url") {
+		return fmt.Errorf("Nothing to change. Pass '--name' and/or '--url'.")
+	}
+
+	feed, err := getOwnedFeed(s, cmd.String("feed-url"), user)
+	if err != nil {
+		return err
+	}
+
+	feedParams := database.UpdateFeedParams{
+		ID:   feed.ID,
+		Name: feed.Name,
+		Url:  feed.Url,
+	}
+	if cmd.IsSet("name") {
+		feedParams.Name = strings.TrimSpace(cmd.String("name"))
+		if feedParams.Name == "" {
+			return fmt.Errorf("Feed name cannot be empty")
+		}
+	}
+	if cmd.IsSet("url") && cmd.String("url") != feed.Url {
+		newURL, err := url.ParseRequestURI(cmd.String("url"))
+		if err != nil || (newURL.Scheme != "http" && newURL.Scheme != "https") {
+			return fmt.Errorf("Invalid URL")
+		}
+
+		_, err = s.DB.GetFeedsByURL(context.Background(), cmd.String("url"))
+		switch {
+		case err == nil:
+			return fmt.Errorf("A feed with the URL '%s' already exists", cmd.String("url"))
+		case !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("Error retrieving feed: %w", err)
+		}
+		feedParams.Url = cmd.String("url")
+	}
+
+	updatedFeed, err := s.DB.UpdateFeed(context.Background(), feedParams)
+	if err != nil {
+		return fmt.Errorf("Error updating feed: %w", err)
+	}
+
+	fmt.Println("Feed successfully updated.")
+	fmt.Printf("\nFeed name: %s\n", updatedFeed.Name)
+	fmt.Printf("Feed URL: %s\n", updatedFeed.Url)
+
+	return nil
+}
+
+// HandlerRmFeed is a handler for the `rmfeed` subcommand. `rmfeed` deletes a feed added by the
+// current user. The feed's posts and follows are deleted along with it so the user is asked to
+// confirm first.
+func HandlerRmFeed(s *State, cmd Command, user database.User) error {
+	feed, err := getOwnedFeed(s, cmd.String("url"), user)
+	if err != nil {
+		return err
+	}
+
+	countParams := database.CountFeedPostsAndFollowsParams{
+		FeedID: feed.ID,
+		UserID: user.ID,
+	}
+	counts, err := s.DB.CountFeedPostsAndFollows(context.Background(), countParams)
+	if err != nil {
+		return fmt.Errorf("Error counting posts and follows: %w", err)
+	}
+
+	fmt.Printf("Deleting '%s' will also delete %d posts, %d stars and %d follows.\n", feed.Name, counts.PostCount, counts.StarCount, counts.FollowCount)
+	if counts.OtherFollowerCount > 0 {
+		fmt.Printf("%d other users follow this feed and will lose its posts, including any that they've starred.\n", counts.OtherFollowerCount)
+	}
+	if !cmd.Bool("yes") {
+		confirmed, err := promptConfirm("Are you sure?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Feed was not deleted.")
+			return nil
+		}
+	}
+
+	err = s.DB.DeleteFeedByID(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("Error deleting feed: %w", err)
+	}
+
+	fmt.Printf("Feed '%s' successfully deleted.\n", feed.Name)
+
+	return nil
+}
+
 func HandlerFeeds(s *State, cmd Command) error {
-	if len(cmd.Args) > 0 {
-		return fmt.Errorf("Command does not take any arguments")
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("errors") && format == "text" {
+		return listFeedErrors(s)
 	}
 
-	feeds, err := s.DB.GetFeeds(context.Background())
+	var feeds []database.Feed
+	if cmd.Bool("errors") {
+		feeds, err = s.DB.GetFeedsWithErrors(context.Background())
+	} else {
+		feeds, err = s.DB.GetFeeds(context.Background())
+	}
 	if err != nil {
 		return fmt.Errorf("Error retrieving feeds: %w", err)
 	}
 
+	if format != "text" {
+		records := make([]Record, 0, len(feeds))
+		for _, feed := range feeds {
+			user, err := s.DB.GetUserByID(context.Background(), feed.UserID)
+			if err != nil {
+				return fmt.Errorf("Error retrieving owner of feed %q: %w", feed.Name, err)
+			}
+			records = append(records, feedRecord(feed, user.Name))
+		}
+		return renderRecords(os.Stdout, format, fieldNames(feedRecord(database.Feed{}, "")), records)
+	}
+
 	for _, feed := range feeds {
 		user, err := s.DB.GetUserByID(context.Background(), feed.UserID)
 		if err != nil {
@@ -241,21 +354,75 @@ func HandlerFeeds(s *State, cmd Command) error {
 	return nil
 }
 
-func HandlerFollow(s *State, cmd Command, user database.User) error {
-	// Validate user input
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("Command expects a URL to an RSS Feed")
-	} else if len(cmd.Args) > 1 {
-		return fmt.Errorf("Command expects only a single argument")
+// HandlerEnableFeed is a handler for the `enablefeed` subcommand. `enablefeed` clears the errors
+// of a feed and re-enables it if it was disabled so that it's fetched on the next run of `agg`.
+func HandlerEnableFeed(s *State, cmd Command) error {
+	feed, err := s.DB.GetFeedsByURL(context.Background(), cmd.String("url"))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("Feed does not exist.")
+		default:
+			return fmt.Errorf("Error retrieving feed: %w", err)
+		}
+	}
+
+	err = s.DB.EnableFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("Error enabling feed: %w", err)
 	}
 
-	_, err := url.ParseRequestURI(cmd.Args[0])
+	fmt.Printf("Feed %q enabled. It will be fetched on the next run of 'agg'.\n", feed.Name)
+
+	return nil
+}
+
+// listFeedErrors prints all of the feeds that failed to be fetched the last time around along with
+// the reason why
+func listFeedErrors(s *State) error {
+	feeds, err := s.DB.GetFeedsWithErrors(context.Background())
+	if err != nil {
+		return fmt.Errorf("Error retrieving feeds: %w", err)
+	}
+
+	if len(feeds) == 0 {
+		fmt.Println("All feeds are being fetched without errors.")
+		return nil
+	}
+
+	for _, feed := range feeds {
+		fmt.Printf("Feed name: %s\n", feed.Name)
+		fmt.Printf("Feed URL: %s\n", feed.Url)
+		if feed.Disabled {
+			fmt.Println("Status: disabled")
+		} else {
+			fmt.Println("Status: failing")
+		}
+		fmt.Printf("Consecutive failures: %d\n", feed.ConsecutiveFailures)
+		fmt.Printf("Last error: %s\n", feed.LastError.String)
+		if feed.LastSuccessAt.Valid {
+			fmt.Printf("Last success: %s\n", feed.LastSuccessAt.Time.String())
+		} else {
+			fmt.Printf("Last success: never\n")
+		}
+		if feed.Disabled {
+			fmt.Printf("Run 'gator enablefeed %s' to fetch it again.\n", feed.Url)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func HandlerFollow(s *State, cmd Command, user database.User) error {
+	// Validate user input
+	_, err := url.ParseRequestURI(cmd.String("url"))
 	if err != nil {
 		return fmt.Errorf("Invalid URL")
 	}
 
 	// Check that feed exists
-	feed, err := s.DB.GetFeedsByURL(context.Background(), cmd.Args[0])
+	feed, err := s.DB.GetFeedsByURL(context.Background(), cmd.String("url"))
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -283,9 +450,9 @@ func HandlerFollow(s *State, cmd Command, user database.User) error {
 }
 
 func HandlerFollowing(s *State, cmd Command, user database.User) error {
-	// Validate user input. Make sure that command doesn't take any input.
-	if len(cmd.Args) > 0 {
-		return fmt.Errorf("Command does not take any arguments")
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
 	}
 
 	feedFollows, err := s.DB.GetFeedFollowsForUser(context.Background(), user.ID)
@@ -299,6 +466,14 @@ func HandlerFollowing(s *State, cmd Command, user database.User) error {
 		}
 	}
 
+	if format != "text" {
+		records := make([]Record, 0, len(feedFollows))
+		for _, feedFollow := range feedFollows {
+			records = append(records, feedFollowRecord(feedFollow))
+		}
+		return renderRecords(os.Stdout, format, fieldNames(feedFollowRecord(database.GetFeedFollowsForUserRow{})), records)
+	}
+
 	if len(feedFollows) == 0 {
 		fmt.Println("You aren't currently following anything. Use the 'follow' command to follow a feed.")
 	} else {
@@ -312,15 +487,8 @@ func HandlerFollowing(s *State, cmd Command, user database.User) error {
 }
 
 func HandlerUnfollow(s *State, cmd Command, user database.User) error {
-	// Validate user input. Make sure that command only takes a feed's URL
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("Command expects the feed URL.")
-	} else if len(cmd.Args) > 1 {
-		return fmt.Errorf("Too many arguments. Make sure you are only passing the feed URL.")
-	}
-
 	// Get feed from URL
-	feed, err := s.DB.GetFeedsByURL(context.Background(), cmd.Args[0])
+	feed, err := s.DB.GetFeedsByURL(context.Background(), cmd.String("url"))
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -344,44 +512,601 @@ func HandlerUnfollow(s *State, cmd Command, user database.User) error {
 }
 
 func HandlerBrowse(s *State, cmd Command, user database.User) error {
-	// Validate user input. Takes an optional "limit" parameter with a default of 2
-	var err error
-	postLimit := 2
-	if len(cmd.Args) > 1 {
-		return fmt.Errorf(`Too many arguments. You may choose to add the maximum number of posts to display 
-            as an integer. Defaults to 2.`)
-	} else if len(cmd.Args) == 1 {
-		postLimit, err = strconv.Atoi(cmd.Args[0])
+	// Validate user input
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	postLimit := cmd.Int("limit")
+	if postLimit < 1 {
+		return fmt.Errorf("Post limit must be a positive integer")
+	}
+	if cmd.Bool("all") && cmd.Bool("unread") {
+		return fmt.Errorf("Only one of '--all' and '--unread' may be used")
+	}
+
+	postParams := database.GetPostsForUserParams{
+		UserID:     user.ID,
+		UnreadOnly: !cmd.Bool("all"),
+		Limit:      int32(postLimit),
+	}
+
+	switch cmd.String("order") {
+	case "desc":
+	case "asc":
+		postParams.Ascending = true
+	default:
+		return fmt.Errorf("Order must be either 'asc' or 'desc'")
+	}
+
+	// Paging. `--page` is just a shorthand for an offset that's a multiple of the limit.
+	if cmd.IsSet("offset") && cmd.IsSet("page") {
+		return fmt.Errorf("Only one of '--offset' and '--page' may be used")
+	}
+	if cmd.IsSet("offset") {
+		if cmd.Int("offset") < 0 {
+			return fmt.Errorf("Offset must be a non-negative integer")
+		}
+		postParams.Offset = int32(cmd.Int("offset"))
+	}
+	if cmd.IsSet("page") {
+		if cmd.Int("page") < 1 {
+			return fmt.Errorf("Page must be a positive integer")
+		}
+		postParams.Offset = int32((cmd.Int("page") - 1) * postLimit)
+	}
+	if cmd.IsSet("cursor") {
+		cursorPublishedAt, cursorID, err := decodePostCursor(cmd.String("cursor"))
 		if err != nil {
-			return fmt.Errorf("Error parsing post limit string: %w", err)
+			return err
 		}
+		postParams.CursorPublishedAt = sql.NullTime{Time: cursorPublishedAt, Valid: true}
+		postParams.CursorID = sql.NullInt32{Int32: cursorID, Valid: true}
 	}
 
-	// Get posts for the current user and display them
-	postParams := database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(postLimit),
+	// Filtering
+	if cmd.IsSet("feed") {
+		feed, err := getFeedByNameOrURL(s, cmd.String("feed"))
+		if err != nil {
+			return err
+		}
+		postParams.FeedID = sql.NullInt32{Int32: feed.ID, Valid: true}
+	}
+	if cmd.IsSet("since") {
+		since, err := rss.ParseDate(cmd.String("since"))
+		if err != nil {
+			return fmt.Errorf("Error parsing '--since' date: %w", err)
+		}
+		postParams.Since = sql.NullTime{Time: since, Valid: true}
 	}
+	if cmd.IsSet("until") {
+		until, err := rss.ParseDate(cmd.String("until"))
+		if err != nil {
+			return fmt.Errorf("Error parsing '--until' date: %w", err)
+		}
+		postParams.Until = sql.NullTime{Time: until, Valid: true}
+	}
+
+	// Get posts for the current user and display them
 	userPosts, err := s.DB.GetPostsForUser(context.Background(), postParams)
 	if err != nil {
 		return fmt.Errorf("Error retrieving posts: %w", err)
 	}
 
-	nUserPosts := len(userPosts)
-	for i, post := range userPosts {
+	// Each post comes with the cursor that shows the posts after it so that scripts can page
+	// through all of the posts
+	if format != "text" {
+		records := make([]Record, 0, len(userPosts))
+		for _, post := range userPosts {
+			records = append(records, browseRecord(post))
+		}
+		return renderRecords(os.Stdout, format, fieldNames(browseRecord(database.GetPostsForUserRow{})), records)
+	}
+
+	if len(userPosts) == 0 {
+		if postParams.UnreadOnly {
+			fmt.Println("You're all caught up! Use '--all' to include posts you've already read.")
+		} else {
+			fmt.Println("No posts found.")
+		}
+		return nil
+	}
+
+	err = printPosts(s, userPosts, cmd.Bool("full"))
+	if err != nil {
+		return err
+	}
+
+	// A full page means that there may be more posts to show
+	if len(userPosts) == postLimit {
+		lastPost := userPosts[len(userPosts)-1]
+		fmt.Printf("\nTo see the next page of posts, use '--cursor %s'\n", encodePostCursor(lastPost))
+	}
+
+	return nil
+}
+
+// encodePostCursor returns a cursor pointing to the given post. Passing the cursor to `browse`
+// shows the posts that come after it.
+func encodePostCursor(post database.GetPostsForUserRow) string {
+	return fmt.Sprintf("%d-%d", post.PublishedAt.Unix(), post.ID)
+}
+
+func decodePostCursor(cursor string) (time.Time, int32, error) {
+	publishedAtStr, idStr, ok := strings.Cut(cursor, "-")
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("Invalid cursor '%s'", cursor)
+	}
+	publishedAt, err := strconv.ParseInt(publishedAtStr, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("Invalid cursor '%s'", cursor)
+	}
+	postID, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("Invalid cursor '%s'", cursor)
+	}
+	return time.Unix(publishedAt, 0), int32(postID), nil
+}
+
+// printPosts prints a list of posts along with any podcast metadata and media files attached to
+// them. If showFull is true then the full content of each post is printed instead of the
+// description.
+func printPosts(s *State, posts []database.GetPostsForUserRow, showFull bool) error {
+	nPosts := len(posts)
+	for i, post := range posts {
+		fmt.Printf("\nID: %d\n", post.ID)
 		fmt.Printf("\nTitle: %s\n", post.Title)
 		fmt.Printf("\nURL: %s\n", post.Url)
 		fmt.Printf("\nPublish Date: %s\n", post.PublishedAt.String())
-		if post.Description.Valid {
-			fmt.Printf("\nDescription:\n%s\n", post.Description.String)
+		if showFull && post.Content.Valid {
+			fmt.Printf("\nContent:\n%s\n", htmltext.Render(post.Content.String, postTextWidth))
+		} else if post.Description.Valid {
+			fmt.Printf("\nDescription:\n%s\n", htmltext.Render(post.Description.String, postTextWidth))
 		} else {
 			fmt.Println("\nDescription: N/A")
 		}
 
-		if (nUserPosts > 1) && (i < nUserPosts-1) {
+		if post.Episode.Valid {
+			fmt.Printf("\nEpisode: %d\n", post.Episode.Int32)
+		}
+		if post.Duration.Valid {
+			fmt.Printf("\nDuration: %s\n", post.Duration.String)
+		}
+		if post.ImageUrl.Valid {
+			fmt.Printf("\nImage: %s\n", post.ImageUrl.String)
+		}
+
+		enclosures, err := s.DB.GetEnclosuresForPost(context.Background(), post.ID)
+		if err != nil {
+			return fmt.Errorf("Error retrieving enclosures: %w", err)
+		}
+		for _, enclosure := range enclosures {
+			printEnclosure(enclosure)
+		}
+
+		if (nPosts > 1) && (i < nPosts-1) {
+			fmt.Println("\n============")
+		}
+	}
+
+	return nil
+}
+
+// renderPosts writes a list of posts in one of the machine-readable output formats
+func renderPosts(format string, posts []database.GetPostsForUserRow) error {
+	records := make([]Record, 0, len(posts))
+	for _, post := range posts {
+		records = append(records, postRecord(post))
+	}
+	return renderRecords(os.Stdout, format, fieldNames(postRecord(database.GetPostsForUserRow{})), records)
+}
+
+// printEnclosure prints a media file attached to a post
+func printEnclosure(enclosure database.PostEnclosure) {
+	fmt.Printf("\nEnclosure: %s\n", enclosure.Url)
+	if enclosure.MimeType.Valid {
+		fmt.Printf("Type: %s\n", enclosure.MimeType.String)
+	}
+	if enclosure.Length.Valid {
+		fmt.Printf("Size: %.1f MB\n", float64(enclosure.Length.Int64)/1_000_000)
+	}
+}
+
+// HandlerImport is a handler for the `import` subcommand. `import` adds and follows all of the
+// feeds in an OPML file. Either all of the feeds are imported or none of them are.
+func HandlerImport(s *State, cmd Command, user database.User) error {
+	opmlFile, err := os.Open(cmd.String("file"))
+	if err != nil {
+		return fmt.Errorf("Error opening OPML file: %w", err)
+	}
+	defer opmlFile.Close()
+
+	doc, err := opml.Parse(opmlFile)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.Conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.DB.WithTx(tx)
+
+	var added, existing, invalid []opml.Feed
+	seen := make(map[string]bool)
+	for _, opmlFeed := range doc.Feeds() {
+		feedURL, err := url.ParseRequestURI(opmlFeed.URL)
+		if err != nil || (feedURL.Scheme != "http" && feedURL.Scheme != "https") {
+			invalid = append(invalid, opmlFeed)
+			continue
+		}
+		if seen[opmlFeed.URL] {
+			continue
+		}
+		seen[opmlFeed.URL] = true
+
+		if opmlFeed.Title == "" {
+			opmlFeed.Title = opmlFeed.URL
+		}
+
+		// Create the feed if it doesn't exist yet
+		feed, err := qtx.GetFeedsByURL(context.Background(), opmlFeed.URL)
+		switch {
+		case err == nil:
+			existing = append(existing, opmlFeed)
+		case errors.Is(err, sql.ErrNoRows):
+			rssFeedParams := database.CreateFeedParams{
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				Name:      opmlFeed.Title,
+				Url:       opmlFeed.URL,
+				UserID:    user.ID,
+			}
+			feed, err = qtx.CreateFeed(context.Background(), rssFeedParams)
+			if err != nil {
+				return fmt.Errorf("Error saving feed %q: %w", opmlFeed.URL, err)
+			}
+			added = append(added, opmlFeed)
+		default:
+			return fmt.Errorf("Error retrieving feed %q: %w", opmlFeed.URL, err)
+		}
+
+		feedFollowEntry := database.FollowFeedIfNotFollowingParams{
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+			Folder: sql.NullString{
+				String: opmlFeed.Folder,
+				Valid:  opmlFeed.Folder != "",
+			},
+		}
+		_, err = qtx.FollowFeedIfNotFollowing(context.Background(), feedFollowEntry)
+		if err != nil {
+			return fmt.Errorf("Error creating feed-follow entry for %q: %w", opmlFeed.URL, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("Error committing import: %w", err)
+	}
+
+	printImportResults("Added", added)
+	printImportResults("Already present", existing)
+	printImportResults("Invalid", invalid)
+
+	return nil
+}
+
+func printImportResults(heading string, feeds []opml.Feed) {
+	fmt.Printf("%s (%d):\n", heading, len(feeds))
+	for _, feed := range feeds {
+		if feed.URL == "" {
+			fmt.Printf(" - %s\n", feed.Title)
+		} else {
+			fmt.Printf(" - %s (%s)\n", feed.Title, feed.URL)
+		}
+	}
+	fmt.Println()
+}
+
+// HandlerExport is a handler for the `export` subcommand. `export` prints the feeds that the
+// current user follows in the given format. Only OPML is currently supported.
+func HandlerExport(s *State, cmd Command, user database.User) error {
+	format := cmd.String("format")
+	if format != "opml" {
+		return fmt.Errorf("Unsupported export format '%s'. Supported formats: opml", format)
+	}
+
+	feedFollows, err := s.DB.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("Could not retrieve feeds for current user: %w", err)
+	}
+
+	feeds := make([]opml.Feed, 0, len(feedFollows))
+	for _, feedFollow := range feedFollows {
+		feeds = append(feeds, opml.Feed{
+			Title:  feedFollow.FeedName,
+			URL:    feedFollow.FeedUrl,
+			Folder: feedFollow.Folder.String,
+		})
+	}
+
+	title := fmt.Sprintf("gator subscriptions for %s", user.Name)
+	return opml.New(title, time.Now(), feeds).Write(os.Stdout)
+}
+
+// HandlerRead is a handler for the `read` subcommand. `read` marks a post as read for the current
+// user.
+func HandlerRead(s *State, cmd Command, user database.User) error {
+	post, err := getPostForUser(s, cmd.Int("post-id"), user)
+	if err != nil {
+		return err
+	}
+
+	postReadParams := database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	}
+	err = s.DB.MarkPostRead(context.Background(), postReadParams)
+	if err != nil {
+		return fmt.Errorf("Error marking post as read: %w", err)
+	}
+
+	fmt.Printf("Marked '%s' as read.\n", post.Title)
+
+	return nil
+}
+
+// HandlerOpen is a handler for the `open` subcommand. `open` opens a post in the user's browser
+// and marks it as read.
+func HandlerOpen(s *State, cmd Command, user database.User) error {
+	post, err := getPostForUser(s, cmd.Int("post-id"), user)
+	if err != nil {
+		return err
+	}
+
+	err = openInBrowser(s.Config.Browser(), post.Url)
+	if err != nil {
+		return err
+	}
+
+	postReadParams := database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	}
+	err = s.DB.MarkPostRead(context.Background(), postReadParams)
+	if err != nil {
+		return fmt.Errorf("Error marking post as read: %w", err)
+	}
+
+	fmt.Printf("Opened '%s' in your browser.\n", post.Title)
+
+	return nil
+}
+
+// HandlerMarkAllRead is a handler for the `mark-all-read` subcommand. `mark-all-read` marks all
+// posts from the feeds that the current user follows as read. If a feed URL is given then only
+// the posts from that feed are marked as read.
+func HandlerMarkAllRead(s *State, cmd Command, user database.User) error {
+	var nMarked int64
+	var err error
+	if !cmd.IsSet("feed-url") {
+		markAllParams := database.MarkAllPostsReadParams{
+			UserID: user.ID,
+			ReadAt: time.Now(),
+		}
+		nMarked, err = s.DB.MarkAllPostsRead(context.Background(), markAllParams)
+		if err != nil {
+			return fmt.Errorf("Error marking posts as read: %w", err)
+		}
+	} else {
+		// Only the feeds that the user follows can be marked as read
+		feedParams := database.GetFollowedFeedByURLParams{
+			Url:    cmd.String("feed-url"),
+			UserID: user.ID,
+		}
+		feed, err := s.DB.GetFollowedFeedByURL(context.Background(), feedParams)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return fmt.Errorf("Feed does not exist.")
+			default:
+				return err
+			}
+		}
+
+		markFeedParams := database.MarkAllFeedPostsReadParams{
+			UserID: user.ID,
+			ReadAt: time.Now(),
+			FeedID: feed.ID,
+		}
+		nMarked, err = s.DB.MarkAllFeedPostsRead(context.Background(), markFeedParams)
+		if err != nil {
+			return fmt.Errorf("Error marking posts as read: %w", err)
+		}
+	}
+
+	fmt.Printf("Marked %d posts as read.\n", nMarked)
+
+	return nil
+}
+
+// HandlerStar is a handler for the `star` subcommand. `star` saves a post so that the current user
+// can find it again later with the `starred` subcommand.
+func HandlerStar(s *State, cmd Command, user database.User) error {
+	post, err := getPostForUser(s, cmd.Int("post-id"), user)
+	if err != nil {
+		return err
+	}
+
+	starParams := database.StarPostParams{
+		UserID:    user.ID,
+		PostID:    post.ID,
+		StarredAt: time.Now(),
+	}
+	err = s.DB.StarPost(context.Background(), starParams)
+	if err != nil {
+		return fmt.Errorf("Error starring post: %w", err)
+	}
+
+	fmt.Printf("Starred '%s'.\n", post.Title)
+
+	return nil
+}
+
+// HandlerUnstar is a handler for the `unstar` subcommand. `unstar` removes a post from the current
+// user's starred posts.
+func HandlerUnstar(s *State, cmd Command, user database.User) error {
+	// Starred posts are kept after their feed is unfollowed, so they can still be unstarred
+	post, err := getPostByID(s, cmd.Int("post-id"))
+	if err != nil {
+		return err
+	}
+
+	unstarParams := database.UnstarPostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	}
+	nUnstarred, err := s.DB.UnstarPost(context.Background(), unstarParams)
+	if err != nil {
+		return fmt.Errorf("Error unstarring post: %w", err)
+	}
+
+	if nUnstarred == 0 {
+		return fmt.Errorf("Post %d is not starred.", post.ID)
+	}
+	fmt.Printf("Unstarred '%s'.\n", post.Title)
+
+	return nil
+}
+
+// HandlerStarred is a handler for the `starred` subcommand. `starred` lists the current user's
+// starred posts, most recently starred first. Takes an optional "limit" parameter with a default
+// of 10.
+func HandlerStarred(s *State, cmd Command, user database.User) error {
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	postLimit := cmd.Int("limit")
+	if postLimit < 1 {
+		return fmt.Errorf("Post limit must be a positive integer")
+	}
+
+	starredParams := database.GetStarredPostsForUserParams{
+		UserID: user.ID,
+		Limit:  int32(postLimit),
+	}
+	starredPosts, err := s.DB.GetStarredPostsForUser(context.Background(), starredParams)
+	if err != nil {
+		return fmt.Errorf("Error retrieving starred posts: %w", err)
+	}
+
+	// Starred posts have the same columns as the posts that are browsed
+	posts := make([]database.GetPostsForUserRow, 0, len(starredPosts))
+	for _, post := range starredPosts {
+		posts = append(posts, database.GetPostsForUserRow(post))
+	}
+
+	if format != "text" {
+		return renderPosts(format, posts)
+	}
+
+	if len(posts) == 0 {
+		fmt.Println("You haven't starred any posts yet. Use the 'star' command to star a post.")
+		return nil
+	}
+
+	return printPosts(s, posts, false)
+}
+
+// HandlerSearch is a handler for the `search` subcommand. `search` finds the posts from the feeds
+// that the current user follows that best match the given query. The query supports quoted
+// phrases, "or" and excluding words with "-".
+func HandlerSearch(s *State, cmd Command, user database.User) error {
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	searchParams := database.SearchPostsForUserParams{
+		Query:  cmd.String("query"),
+		UserID: user.ID,
+		Limit:  int32(defaultSearchLimit),
+	}
+	results, err := s.DB.SearchPostsForUser(context.Background(), searchParams)
+	if err != nil {
+		return fmt.Errorf("Error searching posts: %w", err)
+	}
+
+	if format != "text" {
+		records := make([]Record, 0, len(results))
+		for _, result := range results {
+			records = append(records, searchResultRecord(result))
+		}
+		return renderRecords(os.Stdout, format, fieldNames(searchResultRecord(database.SearchPostsForUserRow{})), records)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No posts matching '%s'.\n", searchParams.Query)
+		return nil
+	}
+
+	nResults := len(results)
+	for i, result := range results {
+		fmt.Printf("\nID: %d\n", result.ID)
+		fmt.Printf("\nTitle: %s\n", result.Title)
+		fmt.Printf("\nFeed: %s\n", result.FeedName)
+		fmt.Printf("\nURL: %s\n", result.Url)
+		fmt.Printf("\nPublish Date: %s\n", result.PublishedAt.String())
+		if snippet := htmltext.Render(result.Snippet, postTextWidth); snippet != "" {
+			fmt.Printf("\nSnippet:\n%s\n", snippet)
+		}
+
+		if (nResults > 1) && (i < nResults-1) {
 			fmt.Println("\n============")
 		}
 	}
 
 	return nil
 }
+
+// getPostForUser looks up a post using an ID passed as a command argument. Only posts from the
+// feeds that the user follows are found.
+func getPostForUser(s *State, postID int, user database.User) (database.GetPostsForUserRow, error) {
+	postParams := database.GetPostForUserParams{
+		ID:     int32(postID),
+		UserID: user.ID,
+	}
+	post, err := s.DB.GetPostForUser(context.Background(), postParams)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return database.GetPostsForUserRow{}, fmt.Errorf("Post %d does not exist.", postID)
+		default:
+			return database.GetPostsForUserRow{}, fmt.Errorf("Error retrieving post: %w", err)
+		}
+	}
+
+	return database.GetPostsForUserRow(post), nil
+}
+
+// getPostByID looks up a post using an ID passed as a command argument
+func getPostByID(s *State, postID int) (database.GetPostsForUserRow, error) {
+	post, err := s.DB.GetPostByID(context.Background(), int32(postID))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return database.GetPostsForUserRow{}, fmt.Errorf("Post %d does not exist.", postID)
+		default:
+			return database.GetPostsForUserRow{}, fmt.Errorf("Error retrieving post: %w", err)
+		}
+	}
+
+	return database.GetPostsForUserRow(post), nil
+}
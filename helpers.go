@@ -1,47 +1,141 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"regexp"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/TheSeaGiraffe/gator/internal/database"
 	"github.com/TheSeaGiraffe/gator/internal/rss"
 )
 
-func scrapeFeeds(s *State) error {
-	// Get next feed to fetch from DB and mark it as fetched
-	feed, err := s.DB.GetNextFeedToFetch(context.Background())
+// scrapeFeeds fetches every feed that is due to be fetched using the given number of workers.
+// Feeds are claimed with `FOR UPDATE SKIP LOCKED` so that neither the workers nor any other
+// running `agg` processes fetch the same feed at the same time.
+func scrapeFeeds(s *State, workers int) error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, workers)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				feed, err := s.DB.ClaimNextFeedToFetch(context.Background())
+				if err != nil {
+					switch {
+					case errors.Is(err, sql.ErrNoRows):
+						// No more feeds left to fetch for this run
+					default:
+						errCh <- fmt.Errorf("Error getting next feed: %w", err)
+					}
+					return
+				}
+
+				err = scrapeFeed(s, feed)
+				if err != nil {
+					errCh <- fmt.Errorf("Error updating feed %q: %w", feed.Name, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// scrapeFeed fetches a single feed, saves all of its posts to the database and schedules the
+// next fetch. Feeds that can't be fetched, parsed or saved are recorded as failing and backed
+// off, so only errors from scheduling the feed are returned.
+func scrapeFeed(s *State, feed database.Feed) error {
+	// Fetch feed using URL. Send the cache headers from the previous fetch so that unchanged
+	// feeds aren't downloaded again.
+	cacheHeaders := rss.CacheHeaders{
+		ETag:         feed.Etag.String,
+		LastModified: feed.LastModified.String,
+	}
+	rssFeed, newCacheHeaders, err := rss.FetchFeed(context.Background(), feed.Url, cacheHeaders)
+	if err != nil {
+		switch {
+		case errors.Is(err, rss.ErrNotModified):
+			err = s.DB.RecordFeedSuccess(context.Background(), feed.ID)
+			if err != nil {
+				return fmt.Errorf("Error recording successful fetch: %w", err)
+			}
+			return scheduleNextFetch(s, feed, 0, 0)
+		default:
+			// A broken feed shouldn't stop the other feeds from being fetched so just record
+			// the failure and move on to the next one
+			fmt.Printf("Error fetching feed %q: %s\n", feed.Name, err.Error())
+			return scheduleRetry(s, feed, err)
+		}
+	}
+
+	// A feed with a post that can't be saved, such as one containing characters that Postgres
+	// doesn't accept, is treated like a feed that can't be fetched so that it doesn't stop the
+	// other feeds from being saved
+	newPosts, err := savePosts(s, feed, rssFeed.Channel.Item)
 	if err != nil {
-		return fmt.Errorf("Error getting next feed: %w", err)
+		fmt.Printf("Error saving posts of feed %q: %s\n", feed.Name, err.Error())
+		return scheduleRetry(s, feed, err)
 	}
 
-	err = s.DB.MarkFeedFetched(context.Background(), feed.ID)
+	// Only save the cache headers once all of the posts have been saved so that a failed save
+	// doesn't cause the feed to be skipped on the next fetch
+	cacheHeaderParams := database.UpdateFeedCacheHeadersParams{
+		ID: feed.ID,
+		Etag: sql.NullString{
+			String: newCacheHeaders.ETag,
+			Valid:  newCacheHeaders.ETag != "",
+		},
+		LastModified: sql.NullString{
+			String: newCacheHeaders.LastModified,
+			Valid:  newCacheHeaders.LastModified != "",
+		},
+	}
+	err = s.DB.UpdateFeedCacheHeaders(context.Background(), cacheHeaderParams)
 	if err != nil {
-		return fmt.Errorf("Error marking feed as fetched: %w", err)
+		return fmt.Errorf("Error saving feed cache headers: %w", err)
 	}
 
-	// Fetch feed using URL
-	rssFeed, err := rss.FetchFeed(context.Background(), feed.Url)
+	err = s.DB.RecordFeedSuccess(context.Background(), feed.ID)
 	if err != nil {
-		return fmt.Errorf("Error fetching feed from URL: %w", err)
+		return fmt.Errorf("Error recording successful fetch: %w", err)
 	}
 
-	// Save all posts in feed to database
-	for _, item := range rssFeed.Channel.Item {
-		// Parse `PublishedAt` time string
-		publishedAtTime, err := parsePublishTime(item.PubDate)
+	return scheduleNextFetch(s, feed, newPosts, rssFeed.UpdateInterval())
+}
+
+// savePosts saves all of the items in a feed to the database and returns the number of new posts
+func savePosts(s *State, feed database.Feed, items []rss.RSSItem) (int, error) {
+	newPosts := 0
+	for _, item := range items {
+		// Parse `PublishedAt` time string. Fall back to the current time for items without a
+		// usable date since `published_at` can't be null. Posts that already exist keep the
+		// date they were first saved with.
+		publishedAtTime, err := rss.ParseDate(item.PubDate)
 		if err != nil {
-			switch {
-			case err.Error() == "No suitable matches":
-				// Use the current time for now; will think of better solution later
-				publishedAtTime = time.Now()
-			default:
-				return err
+			if item.PubDate != "" {
+				fmt.Printf("Could not parse publication date %q of post %q in feed %q\n", item.PubDate, item.Title, feed.Name)
 			}
+			publishedAtTime = time.Now()
 		}
 
 		// Create `sql.NullString` object
@@ -50,62 +144,284 @@ func scrapeFeeds(s *State) error {
 			Valid:  item.Description != "",
 		}
 
-		// Save post to DB
+		contentString := sql.NullString{
+			String: item.Content,
+			Valid:  item.Content != "",
+		}
+
+		guid := postGUID(item)
+
+		// Posts saved before the feed provided GUIDs, including all posts saved before GUIDs
+		// were tracked, were identified by their link. Give those posts their real GUID so
+		// that they aren't saved a second time.
+		if guid != item.Link && item.Link != "" {
+			adoptParams := database.AdoptPostByURLParams{
+				Guid:   guid,
+				FeedID: feed.ID,
+				Url:    item.Link,
+			}
+			err = s.DB.AdoptPostByURL(context.Background(), adoptParams)
+			if err != nil {
+				return 0, fmt.Errorf("Error updating post GUID: %w", err)
+			}
+		}
+
+		episode, err := strconv.Atoi(strings.TrimSpace(item.ITunesEpisode))
+		episodeInt := sql.NullInt32{
+			Int32: int32(episode),
+			Valid: err == nil,
+		}
+
+		// Save post to DB. Posts that already exist are updated if the publisher has edited
+		// them and left alone otherwise.
+		now := time.Now()
 		newPost := database.CreatePostParams{
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
 			Title:       item.Title,
 			Url:         item.Link,
 			Description: descString,
 			PublishedAt: publishedAtTime,
 			FeedID:      feed.ID,
+			Guid:        guid,
+			Content:     contentString,
+			Duration: sql.NullString{
+				String: item.ITunesDuration,
+				Valid:  item.ITunesDuration != "",
+			},
+			Episode: episodeInt,
+			ImageUrl: sql.NullString{
+				String: item.ITunesImage.Href,
+				Valid:  item.ITunesImage.Href != "",
+			},
 		}
 
-		_, err = s.DB.CreatePost(context.Background(), newPost)
-		if (err != nil) && (err.Error() != `pq: duplicate key value violates unique constraint "posts_url_key"`) {
-			return err
+		var postID int32
+		post, err := s.DB.CreatePost(context.Background(), newPost)
+		switch {
+		case err == nil:
+			postID = post.ID
+
+			// Updated posts keep their original creation time
+			if post.CreatedAt.Equal(post.UpdatedAt) {
+				newPosts++
+			}
+		case errors.Is(err, sql.ErrNoRows):
+			// Post already exists and hasn't changed. Its enclosures are still saved since they
+			// may not have been saved the first time around.
+			postParams := database.GetPostIDByGUIDParams{
+				FeedID: feed.ID,
+				Guid:   guid,
+			}
+			postID, err = s.DB.GetPostIDByGUID(context.Background(), postParams)
+			if err != nil {
+				return 0, fmt.Errorf("Error retrieving post: %w", err)
+			}
+		default:
+			return 0, fmt.Errorf("Error saving post: %w", err)
+		}
+
+		err = saveEnclosures(s, postID, item)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return newPosts, nil
+}
+
+// postGUID returns the string used to identify an item within its feed. This is the GUID of the
+// item, falling back to the link for feeds that don't provide one. Items with neither are
+// identified by a hash of their title, date and text so that they don't all end up as the same
+// post.
+func postGUID(item rss.RSSItem) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	if item.Link != "" {
+		return item.Link
+	}
+
+	hash := sha256.New()
+	for _, field := range []string{item.Title, item.PubDate, item.Description, item.Content} {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// saveEnclosures saves the media files attached to an item to the database
+func saveEnclosures(s *State, postID int32, item rss.RSSItem) error {
+	for _, enclosure := range item.Enclosures {
+		if enclosure.URL == "" {
+			continue
+		}
+
+		length, err := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64)
+		lengthInt := sql.NullInt64{
+			Int64: length,
+			Valid: err == nil && length > 0,
+		}
+		now := time.Now()
+		enclosureParams := database.CreatePostEnclosureParams{
+			CreatedAt: now,
+			UpdatedAt: now,
+			PostID:    postID,
+			Url:       enclosure.URL,
+			MimeType: sql.NullString{
+				String: enclosure.Type,
+				Valid:  enclosure.Type != "",
+			},
+			Length: lengthInt,
+		}
+		err = s.DB.CreatePostEnclosure(context.Background(), enclosureParams)
+		if err != nil {
+			return fmt.Errorf("Error saving enclosure: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func parsePublishTime(timeStr string) (time.Time, error) {
-	// Get the relevant parts of the time string using regexes
-	matches, err := getTimeStrParts(timeStr)
+// discoverFeedURL finds the URL of the feed for the given page. If the page advertises more than
+// one feed then the user is asked to pick one.
+func discoverFeedURL(pageURL string) (string, error) {
+	feeds, err := rss.DiscoverFeeds(context.Background(), pageURL)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("Error extracting parts of time string: %w", err)
+		return "", fmt.Errorf("Error looking for feeds: %w", err)
 	}
-	if len(matches) == 0 {
-		return time.Time{}, fmt.Errorf("No suitable matches")
+
+	switch len(feeds) {
+	case 0:
+		return "", fmt.Errorf("Could not find any feeds at '%s'", pageURL)
+	case 1:
+		if feeds[0].URL != pageURL {
+			fmt.Printf("Found feed at '%s'\n", feeds[0].URL)
+		}
+		return feeds[0].URL, nil
+	}
+
+	fmt.Printf("Found %d feeds at '%s':\n\n", len(feeds), pageURL)
+	for i, feed := range feeds {
+		if feed.Title != "" {
+			fmt.Printf(" %d. %s (%s)\n", i+1, feed.Title, feed.URL)
+		} else {
+			fmt.Printf(" %d. %s\n", i+1, feed.URL)
+		}
+	}
+
+	choice, err := promptChoice("\nWhich feed would you like to add?", len(feeds))
+	if err != nil {
+		return "", err
 	}
+	return feeds[choice-1].URL, nil
+}
 
-	// Construct custom time format string and attempt to parse timeStr
-	var formatStrBuilder strings.Builder
-	formatStrBuilder.WriteString("Mon, 02 Jan 2006")
-	if len(strings.Split(matches[0], ":")) == 3 {
-		formatStrBuilder.WriteString(" 15:04:05 ")
-	} else {
-		formatStrBuilder.WriteString(" 15:04 ")
+// getFeedByNameOrURL looks up a feed using either its URL or its name
+func getFeedByNameOrURL(s *State, nameOrURL string) (database.Feed, error) {
+	feed, err := s.DB.GetFeedsByURL(context.Background(), nameOrURL)
+	if err == nil {
+		return feed, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("Error retrieving feed: %w", err)
 	}
-	formatStrBuilder.WriteString(matches[1])
 
-	parsedTime, err := time.Parse(formatStrBuilder.String(), timeStr)
+	feeds, err := s.DB.GetFeedsByName(context.Background(), nameOrURL)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("Could not parse time string: %w", err)
+		return database.Feed{}, fmt.Errorf("Error retrieving feed: %w", err)
+	}
+	switch len(feeds) {
+	case 0:
+		return database.Feed{}, fmt.Errorf("Feed '%s' does not exist", nameOrURL)
+	case 1:
+		return feeds[0], nil
+	default:
+		return database.Feed{}, fmt.Errorf("There is more than one feed named '%s'. Use its URL instead.", nameOrURL)
 	}
-	return parsedTime, nil
 }
 
-func getTimeStrParts(timeStr string) ([]string, error) {
-	pattern := `(\d{2}:\d{2}(?::\d{2})?).*(\b[A-Z]+|\+\d{4})$`
-	r, err := regexp.Compile(pattern)
+// openInBrowser opens a URL using the given browser command without waiting for it to exit. The
+// command may include its own arguments, such as "firefox --new-tab". Only http and https URLs
+// are opened, since the URL comes from the feed and is passed to the command as an argument.
+func openInBrowser(browserCommand string, rawURL string) error {
+	args := strings.Fields(browserCommand)
+	if len(args) == 0 {
+		return fmt.Errorf("No browser command configured")
+	}
+
+	// A URL starting with "-" would be taken as an option by the browser command
+	if strings.HasPrefix(rawURL, "-") {
+		return fmt.Errorf("Refusing to open '%s' in the browser: not a valid URL", rawURL)
+	}
+	postURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("Refusing to open '%s' in the browser: %w", rawURL, err)
+	}
+	if postURL.Scheme != "http" && postURL.Scheme != "https" {
+		return fmt.Errorf("Refusing to open '%s' in the browser: only http and https URLs can be opened", rawURL)
+	}
+	if postURL.Host == "" {
+		return fmt.Errorf("Refusing to open '%s' in the browser: the URL has no host", rawURL)
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], rawURL)...)
+	err = cmd.Start()
 	if err != nil {
-		return []string{}, fmt.Errorf("Error compiling regex: %w", err)
+		return fmt.Errorf("Error opening browser: %w", err)
 	}
-	match := r.FindStringSubmatch(timeStr)
-	if len(match) == 0 {
-		return []string{}, nil
+
+	// Reap the process once it exits so that it doesn't linger as a zombie
+	go cmd.Wait()
+
+	return nil
+}
+
+// promptConfirm asks the user a yes or no question. Anything other than "y" or "yes" counts as no.
+func promptConfirm(prompt string) (bool, error) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("%s [y/N]: ", prompt)
+	input, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("Error reading input: %w", err)
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(input))
+	return answer == "y" || answer == "yes", nil
+}
+
+// getOwnedFeed looks up a feed by its URL and makes sure that it was added by the given user
+func getOwnedFeed(s *State, feedURL string, user database.User) (database.Feed, error) {
+	feed, err := s.DB.GetFeedsByURL(context.Background(), feedURL)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return database.Feed{}, fmt.Errorf("Feed does not exist.")
+		default:
+			return database.Feed{}, fmt.Errorf("Error retrieving feed: %w", err)
+		}
+	}
+
+	if feed.UserID != user.ID {
+		return database.Feed{}, fmt.Errorf("Only the user who added '%s' can change it.", feed.Name)
+	}
+
+	return feed, nil
+}
+
+// promptChoice asks the user to pick a number between 1 and n
+func promptChoice(prompt string, n int) (int, error) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("%s [1-%d]: ", prompt, n)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, fmt.Errorf("Error reading input: %w", err)
+		}
+
+		choice, err := strconv.Atoi(strings.TrimSpace(input))
+		if err == nil && choice >= 1 && choice <= n {
+			return choice, nil
+		}
+		fmt.Printf("Please enter a number between 1 and %d.\n", n)
 	}
-	return match[1:], nil
 }
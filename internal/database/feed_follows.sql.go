@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,9 +15,9 @@ import (
 
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH new_record AS (
-    INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id)
-    VALUES($1, $2, $3, $4)
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id, folder)
+    VALUES($1, $2, $3, $4, $5)
+    RETURNING id, created_at, updated_at, user_id, feed_id, folder
 )
 SELECT
     new_record.id,
@@ -34,6 +35,7 @@ type CreateFeedFollowParams struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    int32
+	Folder    sql.NullString
 }
 
 type CreateFeedFollowRow struct {
@@ -50,6 +52,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		arg.UpdatedAt,
 		arg.UserID,
 		arg.FeedID,
+		arg.Folder,
 	)
 	var i CreateFeedFollowRow
 	err := row.Scan(
@@ -79,19 +82,58 @@ func (q *Queries) DeleteFeed(ctx context.Context, arg DeleteFeedParams) error {
 	return err
 }
 
+const followFeedIfNotFollowing = `-- name: FollowFeedIfNotFollowing :execrows
+INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id, folder)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, feed_id) DO NOTHING
+`
+
+type FollowFeedIfNotFollowingParams struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    int32
+	Folder    sql.NullString
+}
+
+func (q *Queries) FollowFeedIfNotFollowing(ctx context.Context, arg FollowFeedIfNotFollowingParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, followFeedIfNotFollowing,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+		arg.Folder,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT
+    feed_follows.id,
+    feed_follows.created_at,
+    feed_follows.feed_id,
     users.name AS user_name,
-    feeds.name AS feed_name
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    feed_follows.folder
 FROM feed_follows
 INNER JOIN users ON feed_follows.user_id = users.id
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 WHERE feed_follows.user_id = $1
+ORDER BY feed_follows.folder NULLS FIRST, feeds.name
 `
 
 type GetFeedFollowsForUserRow struct {
-	UserName string
-	FeedName string
+	ID        int32
+	CreatedAt time.Time
+	FeedID    int32
+	UserName  string
+	FeedName  string
+	FeedUrl   string
+	Folder    sql.NullString
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -103,7 +145,15 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	var items []GetFeedFollowsForUserRow
 	for rows.Next() {
 		var i GetFeedFollowsForUserRow
-		if err := rows.Scan(&i.UserName, &i.FeedName); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.FeedID,
+			&i.UserName,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.Folder,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -7,11 +7,92 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const claimNextFeedToFetch = `-- name: ClaimNextFeedToFetch :one
+UPDATE feeds
+SET
+    updated_at = now(),
+    last_fetched_at = now(),
+    next_fetch_at = now() + make_interval(secs => fetch_interval)
+WHERE id = (
+    SELECT id FROM feeds
+    WHERE
+        (next_fetch_at IS NULL OR next_fetch_at <= now()) AND
+        NOT disabled
+    ORDER BY next_fetch_at ASC NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled
+`
+
+func (q *Queries) ClaimNextFeedToFetch(ctx context.Context) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, claimNextFeedToFetch)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+	)
+	return i, err
+}
+
+const countFeedPostsAndFollows = `-- name: CountFeedPostsAndFollows :one
+SELECT
+    (SELECT count(*) FROM posts WHERE posts.feed_id = $1) AS post_count,
+    (
+        SELECT count(*) FROM post_stars
+        INNER JOIN posts ON post_stars.post_id = posts.id
+        WHERE posts.feed_id = $1
+    ) AS star_count,
+    (SELECT count(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS follow_count,
+    (
+        SELECT count(*) FROM feed_follows
+        WHERE feed_follows.feed_id = $1 AND feed_follows.user_id <> $2
+    ) AS other_follower_count
+`
+
+type CountFeedPostsAndFollowsParams struct {
+	FeedID int32
+	UserID uuid.UUID
+}
+
+type CountFeedPostsAndFollowsRow struct {
+	PostCount          int64
+	StarCount          int64
+	FollowCount        int64
+	OtherFollowerCount int64
+}
+
+func (q *Queries) CountFeedPostsAndFollows(ctx context.Context, arg CountFeedPostsAndFollowsParams) (CountFeedPostsAndFollowsRow, error) {
+	row := q.db.QueryRowContext(ctx, countFeedPostsAndFollows, arg.FeedID, arg.UserID)
+	var i CountFeedPostsAndFollowsRow
+	err := row.Scan(
+		&i.PostCount,
+		&i.StarCount,
+		&i.FollowCount,
+		&i.OtherFollowerCount,
+	)
+	return i, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (created_at, updated_at, name, url, user_id)
 VALUES (
@@ -21,7 +102,7 @@ VALUES (
     $4,
     $5
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled
 `
 
 type CreateFeedParams struct {
@@ -49,12 +130,46 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
 	)
 	return i, err
 }
 
+const deleteFeedByID = `-- name: DeleteFeedByID :exec
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeedByID(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedByID, id)
+	return err
+}
+
+const enableFeed = `-- name: EnableFeed :exec
+UPDATE feeds
+SET
+    updated_at = now(),
+    last_error = NULL,
+    consecutive_failures = 0,
+    next_fetch_at = NULL,
+    disabled = false
+WHERE id = $1
+`
+
+func (q *Queries) EnableFeed(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, enableFeed, id)
+	return err
+}
+
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -74,6 +189,58 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.Disabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsByName = `-- name: GetFeedsByName :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled FROM feeds
+WHERE name = $1
+`
+
+func (q *Queries) GetFeedsByName(ctx context.Context, name string) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsByName, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.Disabled,
 		); err != nil {
 			return nil, err
 		}
@@ -89,7 +256,7 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getFeedsByURL = `-- name: GetFeedsByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled FROM feeds
 WHERE url = $1
 `
 
@@ -104,18 +271,169 @@ func (q *Queries) GetFeedsByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+	)
+	return i, err
+}
+
+const getFeedsWithErrors = `-- name: GetFeedsWithErrors :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled FROM feeds
+WHERE consecutive_failures > 0 OR disabled
+ORDER BY consecutive_failures DESC
+`
+
+func (q *Queries) GetFeedsWithErrors(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsWithErrors)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.Disabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowedFeedByURL = `-- name: GetFollowedFeedByURL :one
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.etag, feeds.last_modified, feeds.fetch_interval, feeds.next_fetch_at, feeds.last_error, feeds.consecutive_failures, feeds.last_success_at, feeds.disabled FROM feeds
+INNER JOIN feed_follows ON
+    feeds.id = feed_follows.feed_id
+WHERE
+    feeds.url = $1 AND
+    feed_follows.user_id = $2
+`
+
+type GetFollowedFeedByURLParams struct {
+	Url    string
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetFollowedFeedByURL(ctx context.Context, arg GetFollowedFeedByURLParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFollowedFeedByURL, arg.Url, arg.UserID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
 	)
 	return i, err
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1
+const recordFeedFailure = `-- name: RecordFeedFailure :exec
+UPDATE feeds
+SET
+    updated_at = now(),
+    last_error = $2,
+    consecutive_failures = $3,
+    next_fetch_at = $4,
+    disabled = $5
+WHERE id = $1
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+type RecordFeedFailureParams struct {
+	ID                  int32
+	LastError           sql.NullString
+	ConsecutiveFailures int32
+	NextFetchAt         sql.NullTime
+	Disabled            bool
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedFailure,
+		arg.ID,
+		arg.LastError,
+		arg.ConsecutiveFailures,
+		arg.NextFetchAt,
+		arg.Disabled,
+	)
+	return err
+}
+
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET
+    updated_at = now(),
+    last_error = NULL,
+    consecutive_failures = 0,
+    last_success_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) RecordFeedSuccess(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, id)
+	return err
+}
+
+const updateFeed = `-- name: UpdateFeed :one
+UPDATE feeds
+SET
+    updated_at = now(),
+    name = $2,
+    url = $3,
+    etag = CASE WHEN url = $3 THEN etag END,
+    last_modified = CASE WHEN url = $3 THEN last_modified END,
+    next_fetch_at = CASE WHEN url = $3 THEN next_fetch_at END,
+    last_error = CASE WHEN url = $3 THEN last_error END,
+    consecutive_failures = CASE WHEN url = $3 THEN consecutive_failures ELSE 0 END,
+    disabled = CASE WHEN url = $3 THEN disabled ELSE false END
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_interval, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled
+`
+
+type UpdateFeedParams struct {
+	ID   int32
+	Name string
+	Url  string
+}
+
+func (q *Queries) UpdateFeed(ctx context.Context, arg UpdateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeed, arg.ID, arg.Name, arg.Url)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -125,19 +443,54 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
 	)
 	return i, err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :exec
+const updateFeedCacheHeaders = `-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET
+    updated_at = now(),
+    etag = $2,
+    last_modified = $3
+WHERE id = $1
+`
+
+type UpdateFeedCacheHeadersParams struct {
+	ID           int32
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) UpdateFeedCacheHeaders(ctx context.Context, arg UpdateFeedCacheHeadersParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedCacheHeaders, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
+
+const updateFeedSchedule = `-- name: UpdateFeedSchedule :exec
 UPDATE feeds
 SET
     updated_at = now(),
-    last_fetched_at = now()
+    fetch_interval = $2,
+    next_fetch_at = $3
 WHERE id = $1
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+type UpdateFeedScheduleParams struct {
+	ID            int32
+	FetchInterval int32
+	NextFetchAt   sql.NullTime
+}
+
+func (q *Queries) UpdateFeedSchedule(ctx context.Context, arg UpdateFeedScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedSchedule, arg.ID, arg.FetchInterval, arg.NextFetchAt)
 	return err
 }
@@ -12,13 +12,21 @@ import (
 )
 
 type Feed struct {
-	ID            int32
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                  int32
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	FetchInterval       int32
+	NextFetchAt         sql.NullTime
+	LastError           sql.NullString
+	ConsecutiveFailures int32
+	LastSuccessAt       sql.NullTime
+	Disabled            bool
 }
 
 type FeedFollow struct {
@@ -27,17 +35,46 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    int32
+	Folder    sql.NullString
 }
 
 type Post struct {
-	ID          int32
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt time.Time
-	FeedID      int32
+	ID           int32
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  time.Time
+	FeedID       int32
+	Guid         string
+	Content      sql.NullString
+	SearchVector interface{}
+	Duration     sql.NullString
+	Episode      sql.NullInt32
+	ImageUrl     sql.NullString
+}
+
+type PostEnclosure struct {
+	ID        int32
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	PostID    int32
+	Url       string
+	MimeType  sql.NullString
+	Length    sql.NullInt64
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID int32
+	ReadAt time.Time
+}
+
+type PostStar struct {
+	UserID    uuid.UUID
+	PostID    int32
+	StarredAt time.Time
 }
 
 type User struct {
@@ -13,10 +13,52 @@ import (
 	"github.com/google/uuid"
 )
 
+const adoptPostByURL = `-- name: AdoptPostByURL :exec
+UPDATE posts
+SET guid = $1
+WHERE
+    feed_id = $2 AND
+    guid = $3 AND
+    url = $3 AND
+    NOT EXISTS (
+        SELECT 1 FROM posts AS existing
+        WHERE existing.feed_id = $2 AND existing.guid = $1
+    )
+`
+
+type AdoptPostByURLParams struct {
+	Guid   string
+	FeedID int32
+	Url    string
+}
+
+func (q *Queries) AdoptPostByURL(ctx context.Context, arg AdoptPostByURLParams) error {
+	_, err := q.db.ExecContext(ctx, adoptPostByURL, arg.Guid, arg.FeedID, arg.Url)
+	return err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id, guid, content, duration, episode, image_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET
+    updated_at = EXCLUDED.updated_at,
+    title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    content = EXCLUDED.content,
+    duration = EXCLUDED.duration,
+    episode = EXCLUDED.episode,
+    image_url = EXCLUDED.image_url
+WHERE
+    posts.title IS DISTINCT FROM EXCLUDED.title OR
+    posts.url IS DISTINCT FROM EXCLUDED.url OR
+    posts.description IS DISTINCT FROM EXCLUDED.description OR
+    posts.content IS DISTINCT FROM EXCLUDED.content OR
+    posts.duration IS DISTINCT FROM EXCLUDED.duration OR
+    posts.episode IS DISTINCT FROM EXCLUDED.episode OR
+    posts.image_url IS DISTINCT FROM EXCLUDED.image_url
+RETURNING id, created_at, updated_at
 `
 
 type CreatePostParams struct {
@@ -27,9 +69,20 @@ type CreatePostParams struct {
 	Description sql.NullString
 	PublishedAt time.Time
 	FeedID      int32
+	Guid        string
+	Content     sql.NullString
+	Duration    sql.NullString
+	Episode     sql.NullInt32
+	ImageUrl    sql.NullString
+}
+
+type CreatePostRow struct {
+	ID        int32
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (CreatePostRow, error) {
 	row := q.db.QueryRowContext(ctx, createPost,
 		arg.CreatedAt,
 		arg.UpdatedAt,
@@ -38,8 +91,45 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Guid,
+		arg.Content,
+		arg.Duration,
+		arg.Episode,
+		arg.ImageUrl,
 	)
-	var i Post
+	var i CreatePostRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPostByID = `-- name: GetPostByID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content, duration, episode, image_url FROM posts
+WHERE id = $1
+`
+
+type GetPostByIDRow struct {
+	ID          int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      int32
+	Guid        string
+	Content     sql.NullString
+	Duration    sql.NullString
+	Episode     sql.NullInt32
+	ImageUrl    sql.NullString
+}
+
+func (q *Queries) GetPostByID(ctx context.Context, id int32) (GetPostByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostByID, id)
+	var i GetPostByIDRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
@@ -49,11 +139,16 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Guid,
+		&i.Content,
+		&i.Duration,
+		&i.Episode,
+		&i.ImageUrl,
 	)
 	return i, err
 }
 
-const getPostsForUser = `-- name: GetPostsForUser :many
+const getPostForUser = `-- name: GetPostForUser :one
 SELECT
     posts.id,
     posts.created_at,
@@ -62,29 +157,164 @@ SELECT
     posts.url,
     posts.description,
     posts.published_at,
-    posts.feed_id
+    posts.feed_id,
+    posts.guid,
+    posts.content,
+    posts.duration,
+    posts.episode,
+    posts.image_url
 FROM posts
 INNER JOIN feed_follows ON
     posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2
+WHERE
+    posts.id = $1 AND
+    feed_follows.user_id = $2
 `
 
-type GetPostsForUserParams struct {
+type GetPostForUserParams struct {
+	ID     int32
 	UserID uuid.UUID
-	Limit  int32
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+type GetPostForUserRow struct {
+	ID          int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      int32
+	Guid        string
+	Content     sql.NullString
+	Duration    sql.NullString
+	Episode     sql.NullInt32
+	ImageUrl    sql.NullString
+}
+
+func (q *Queries) GetPostForUser(ctx context.Context, arg GetPostForUserParams) (GetPostForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUser, arg.ID, arg.UserID)
+	var i GetPostForUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.Content,
+		&i.Duration,
+		&i.Episode,
+		&i.ImageUrl,
+	)
+	return i, err
+}
+
+const getPostIDByGUID = `-- name: GetPostIDByGUID :one
+SELECT id FROM posts
+WHERE
+    feed_id = $1 AND
+    guid = $2
+`
+
+type GetPostIDByGUIDParams struct {
+	FeedID int32
+	Guid   string
+}
+
+func (q *Queries) GetPostIDByGUID(ctx context.Context, arg GetPostIDByGUIDParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getPostIDByGUID, arg.FeedID, arg.Guid)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getPostsForUser = `-- name: GetPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.content, posts.duration, posts.episode, posts.image_url
+FROM posts
+INNER JOIN feed_follows ON
+    posts.feed_id = feed_follows.feed_id
+LEFT JOIN post_reads ON
+    posts.id = post_reads.post_id AND
+    post_reads.user_id = feed_follows.user_id
+WHERE
+    feed_follows.user_id = $1 AND
+    (NOT $2::boolean OR post_reads.post_id IS NULL) AND
+    ($3::int IS NULL OR posts.feed_id = $3) AND
+    ($4::timestamptz IS NULL OR posts.published_at >= $4) AND
+    ($5::timestamptz IS NULL OR posts.published_at < $5) AND
+    (
+        $6::timestamptz IS NULL OR
+        (
+            $7::boolean AND
+            (posts.published_at, posts.id) > ($6, $8::int)
+        ) OR
+        (
+            NOT $7::boolean AND
+            (posts.published_at, posts.id) < ($6, $8::int)
+        )
+    )
+ORDER BY
+    CASE WHEN $7::boolean THEN posts.published_at END ASC,
+    CASE WHEN $7::boolean THEN posts.id END ASC,
+    posts.published_at DESC,
+    posts.id DESC
+LIMIT $9
+OFFSET $10
+`
+
+type GetPostsForUserParams struct {
+	UserID            uuid.UUID
+	UnreadOnly        bool
+	FeedID            sql.NullInt32
+	Since             sql.NullTime
+	Until             sql.NullTime
+	CursorPublishedAt sql.NullTime
+	Ascending         bool
+	CursorID          sql.NullInt32
+	Limit             int32
+	Offset            int32
+}
+
+type GetPostsForUserRow struct {
+	ID          int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      int32
+	Guid        string
+	Content     sql.NullString
+	Duration    sql.NullString
+	Episode     sql.NullInt32
+	ImageUrl    sql.NullString
+}
+
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.CursorPublishedAt,
+		arg.Ascending,
+		arg.CursorID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForUserRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForUserRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -94,6 +324,86 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Guid,
+			&i.Content,
+			&i.Duration,
+			&i.Episode,
+			&i.ImageUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(posts.search_vector, query) AS rank,
+    ts_headline(
+        'english',
+        concat_ws(' ', posts.description, posts.content),
+        query,
+        'StartSel=**, StopSel=**, MaxFragments=2, MaxWords=30, MinWords=10'
+    ) AS snippet
+FROM
+    posts
+    INNER JOIN feed_follows ON
+        posts.feed_id = feed_follows.feed_id
+    INNER JOIN feeds ON
+        posts.feed_id = feeds.id,
+    websearch_to_tsquery('english', $1) AS query
+WHERE
+    feed_follows.user_id = $2 AND
+    posts.search_vector @@ query
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $3
+`
+
+type SearchPostsForUserParams struct {
+	Query  string
+	UserID uuid.UUID
+	Limit  int32
+}
+
+type SearchPostsForUserRow struct {
+	ID          int32
+	Title       string
+	Url         string
+	PublishedAt time.Time
+	FeedName    string
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser, arg.Query, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsForUserRow
+	for rows.Next() {
+		var i SearchPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
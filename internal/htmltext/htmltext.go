@@ -0,0 +1,490 @@
+// Package htmltext renders the HTML found in feeds as plain text that is readable in a terminal.
+// Paragraphs are wrapped, lists get bullets, links are turned into numbered footnotes and scripts
+// and styles are stripped.
+package htmltext
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// minWrapWidth keeps deeply nested content from being wrapped into a single word per line
+const minWrapWidth = 20
+
+var attributeRegex = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+))?`)
+
+// rawTextTags contain text that isn't HTML and are dropped along with their content
+var rawTextTags = map[string]bool{
+	"script": true,
+	"style":  true,
+}
+
+// hiddenTags are dropped along with all of the elements inside of them
+var hiddenTags = map[string]bool{
+	"head":     true,
+	"template": true,
+	"svg":      true,
+	"iframe":   true,
+	"select":   true,
+}
+
+// blockTags start a new paragraph
+var blockTags = map[string]bool{
+	"p":       true,
+	"div":     true,
+	"section": true,
+	"article": true,
+	"header":  true,
+	"footer":  true,
+	"main":    true,
+	"aside":   true,
+	"nav":     true,
+	"figure":  true,
+	"table":   true,
+	"dl":      true,
+	"address": true,
+	"details": true,
+	"center":  true,
+}
+
+// lineTags start a new line without leaving a blank line before it
+var lineTags = map[string]bool{
+	"tr":         true,
+	"dt":         true,
+	"dd":         true,
+	"figcaption": true,
+	"summary":    true,
+}
+
+var bullets = []string{"• ", "◦ ", "▪ "}
+
+type tokenType int
+
+const (
+	textToken tokenType = iota
+	startTagToken
+	endTagToken
+)
+
+type token struct {
+	typ         tokenType
+	data        string
+	attrs       map[string]string
+	selfClosing bool
+}
+
+type list struct {
+	ordered bool
+	n       int
+	indent  int
+}
+
+type link struct {
+	href  string
+	start int
+}
+
+type renderer struct {
+	width int
+	lines []string
+
+	inline    strings.Builder
+	gap       bool
+	gapQuotes int
+	bullet    string
+	lists     []list
+	quotes    int
+	pre       int
+	hidden    int
+	links     []link
+	footnotes []string
+	footnoteN map[string]int
+}
+
+// Render converts HTML to plain text, wrapping lines to the given width. A width of 0 or less
+// disables wrapping. Plain text is returned with its whitespace collapsed.
+func Render(htmlStr string, width int) string {
+	r := &renderer{
+		width:     width,
+		footnoteN: make(map[string]int),
+	}
+	for _, tok := range tokenize(htmlStr) {
+		switch tok.typ {
+		case textToken:
+			r.text(tok.data)
+		case startTagToken:
+			r.startTag(tok)
+
+			// Self-closing tags, as found in XHTML content, don't have an end tag of their own
+			if tok.selfClosing {
+				r.endTag(tok.data)
+			}
+		case endTagToken:
+			r.endTag(tok.data)
+		}
+	}
+	r.flush()
+
+	if len(r.footnotes) > 0 {
+		r.lines = append(r.lines, "")
+		for i, href := range r.footnotes {
+			r.lines = append(r.lines, fmt.Sprintf("[%d] %s", i+1, href))
+		}
+	}
+
+	return strings.Join(r.lines, "\n")
+}
+
+func (r *renderer) text(text string) {
+	if r.hidden > 0 {
+		return
+	}
+	r.inline.WriteString(html.UnescapeString(text))
+}
+
+func (r *renderer) startTag(tok token) {
+	if hiddenTags[tok.data] {
+		r.hidden++
+		return
+	}
+	if r.hidden > 0 {
+		return
+	}
+
+	switch name := tok.data; {
+	case blockTags[name]:
+		r.block()
+	case lineTags[name]:
+		r.flush()
+	case name == "br":
+		r.inline.WriteString("\n")
+	case name == "hr":
+		r.block()
+		r.inline.WriteString("---")
+		r.block()
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+		r.block()
+		r.inline.WriteString(strings.Repeat("#", int(name[1]-'0')) + " ")
+	case name == "ul" || name == "ol":
+		if len(r.lists) == 0 {
+			r.block()
+		} else {
+			r.flush()
+		}
+		l := list{ordered: name == "ol", n: 1}
+		if start, err := strconv.Atoi(tok.attrs["start"]); err == nil {
+			l.n = start
+		}
+		r.lists = append(r.lists, l)
+	case name == "li":
+		r.flush()
+		if len(r.lists) == 0 {
+			r.lists = append(r.lists, list{})
+		}
+		l := &r.lists[len(r.lists)-1]
+		if l.ordered {
+			r.bullet = fmt.Sprintf("%d. ", l.n)
+			l.n++
+		} else {
+			r.bullet = bullets[(len(r.lists)-1)%len(bullets)]
+		}
+		l.indent = utf8.RuneCountInString(r.bullet)
+	case name == "blockquote":
+		r.block()
+		r.quotes++
+	case name == "pre":
+		r.block()
+		r.pre++
+	case name == "td" || name == "th":
+		r.inline.WriteString("  ")
+	case name == "a":
+		r.links = append(r.links, link{href: strings.TrimSpace(tok.attrs["href"]), start: r.inline.Len()})
+	case name == "img":
+		if alt := strings.TrimSpace(tok.attrs["alt"]); alt != "" {
+			r.inline.WriteString(fmt.Sprintf(" [image: %s] ", alt))
+		}
+	}
+}
+
+func (r *renderer) endTag(name string) {
+	if hiddenTags[name] {
+		r.hidden = max(r.hidden-1, 0)
+		return
+	}
+	if r.hidden > 0 {
+		return
+	}
+
+	switch {
+	case blockTags[name]:
+		r.block()
+	case lineTags[name] || name == "li":
+		r.flush()
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+		r.block()
+	case name == "ul" || name == "ol":
+		r.flush()
+		if len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+		if len(r.lists) == 0 {
+			r.block()
+		}
+	case name == "blockquote":
+		r.block()
+		r.quotes = max(r.quotes-1, 0)
+	case name == "pre":
+		r.block()
+		r.pre = max(r.pre-1, 0)
+	case name == "a":
+		if len(r.links) == 0 {
+			return
+		}
+		l := r.links[len(r.links)-1]
+		r.links = r.links[:len(r.links)-1]
+		r.footnote(l)
+	}
+}
+
+// footnote adds a footnote marker after the text of a link. Links whose text is already the URL
+// and links within the page don't get one.
+func (r *renderer) footnote(l link) {
+	if l.href == "" || strings.HasPrefix(l.href, "#") || strings.HasPrefix(strings.ToLower(l.href), "javascript:") {
+		return
+	}
+	linkText := strings.TrimSpace(r.inline.String()[min(l.start, r.inline.Len()):])
+	if linkText == l.href {
+		return
+	}
+
+	n, ok := r.footnoteN[l.href]
+	if !ok {
+		r.footnotes = append(r.footnotes, l.href)
+		n = len(r.footnotes)
+		r.footnoteN[l.href] = n
+	}
+	fmt.Fprintf(&r.inline, "[%d]", n)
+}
+
+// block ends the current paragraph and leaves a blank line before the next one. The blank line is
+// only quoted if both paragraphs are inside the same blockquote.
+func (r *renderer) block() {
+	r.flush()
+	if !r.gap {
+		r.gapQuotes = r.quotes
+	}
+	r.gap = true
+	r.gapQuotes = min(r.gapQuotes, r.quotes)
+}
+
+// flush wraps the text collected since the last paragraph break and adds it to the output
+func (r *renderer) flush() {
+	text := r.inline.String()
+	r.inline.Reset()
+
+	prefix := strings.Repeat("> ", r.quotes)
+	for _, l := range r.lists[:max(len(r.lists)-1, 0)] {
+		prefix += strings.Repeat(" ", l.indent)
+	}
+	indent := ""
+	if len(r.lists) > 0 {
+		indent = strings.Repeat(" ", r.lists[len(r.lists)-1].indent)
+	}
+	lead := indent
+	if r.bullet != "" {
+		lead = r.bullet
+	}
+
+	var lines []string
+	if r.pre > 0 {
+		lines = strings.Split(strings.Trim(text, "\n"), "\n")
+		for i := range lines {
+			lines[i] = "    " + strings.TrimRight(lines[i], " \t\r")
+		}
+	} else {
+		wrapWidth := 0
+		if r.width > 0 {
+			wrapWidth = max(r.width-utf8.RuneCountInString(prefix+lead), minWrapWidth)
+		}
+		for _, segment := range strings.Split(text, "\n") {
+			lines = append(lines, wrap(strings.Fields(segment), wrapWidth)...)
+		}
+	}
+	lines = trimBlankLines(lines)
+	if len(lines) == 0 {
+		return
+	}
+
+	if r.gap && len(r.lines) > 0 {
+		r.lines = append(r.lines, strings.TrimRight(strings.Repeat("> ", min(r.gapQuotes, r.quotes)), " "))
+	}
+	r.gap = false
+	r.bullet = ""
+
+	for i, line := range lines {
+		if i > 0 {
+			lead = indent
+		}
+		r.lines = append(r.lines, strings.TrimRight(prefix+lead+line, " "))
+	}
+}
+
+// Wrap wraps plain text, such as the title of a post, to the given width without interpreting
+// any of it as HTML. A width of 0 or less disables wrapping.
+func Wrap(text string, width int) []string {
+	return wrap(strings.Fields(text), width)
+}
+
+// wrap joins words into lines no longer than width. Words that are longer than the width are put
+// on a line of their own. A width of 0 or less puts all of the words on one line.
+func wrap(words []string, width int) []string {
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := words[0]
+	lineLen := utf8.RuneCountInString(line)
+	for _, word := range words[1:] {
+		wordLen := utf8.RuneCountInString(word)
+		if width > 0 && lineLen+1+wordLen > width {
+			lines = append(lines, line)
+			line, lineLen = word, wordLen
+			continue
+		}
+		line += " " + word
+		lineLen += 1 + wordLen
+	}
+	return append(lines, line)
+}
+
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// tokenize splits HTML into text and tags. It's much more forgiving than a real HTML parser and
+// doesn't build a tree, but that's all that's needed to render the markup found in feeds.
+// Comments, doctypes and the content of raw text elements such as scripts are dropped.
+func tokenize(htmlStr string) []token {
+	var tokens []token
+	i := 0
+	for i < len(htmlStr) {
+		lt := strings.IndexByte(htmlStr[i:], '<')
+		if lt < 0 {
+			tokens = append(tokens, token{typ: textToken, data: htmlStr[i:]})
+			break
+		}
+		if lt > 0 {
+			tokens = append(tokens, token{typ: textToken, data: htmlStr[i : i+lt]})
+		}
+		i += lt
+		rest := htmlStr[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				return tokens
+			}
+			i += 4 + end + 3
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(rest, "</"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return tokens
+			}
+			name := strings.ToLower(strings.TrimSpace(rest[2:end]))
+			tokens = append(tokens, token{typ: endTagToken, data: name})
+			i += end + 1
+		case len(rest) > 1 && isLetter(rest[1]):
+			end := tagEnd(rest)
+			if end < 0 {
+				return tokens
+			}
+			tok := parseStartTag(rest[1:end])
+			i += end + 1
+
+			// Skip straight to the end of raw text elements since their content may contain
+			// anything, including things that look like tags
+			if rawTextTags[tok.data] && !tok.selfClosing {
+				closing := strings.Index(strings.ToLower(htmlStr[i:]), "</"+tok.data)
+				if closing < 0 {
+					return tokens
+				}
+				i += closing
+				continue
+			}
+			if !rawTextTags[tok.data] {
+				tokens = append(tokens, tok)
+			}
+		default:
+			tokens = append(tokens, token{typ: textToken, data: "<"})
+			i++
+		}
+	}
+	return tokens
+}
+
+// tagEnd returns the index of the `>` that ends the tag at the start of s, skipping over any
+// `>` inside of quoted attribute values
+func tagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseStartTag(tag string) token {
+	selfClosing := strings.HasSuffix(tag, "/")
+	tag = strings.TrimSuffix(tag, "/")
+	nameEnd := strings.IndexFunc(tag, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '/'
+	})
+	if nameEnd < 0 {
+		nameEnd = len(tag)
+	}
+
+	tok := token{
+		typ:         startTagToken,
+		data:        strings.ToLower(tag[:nameEnd]),
+		attrs:       make(map[string]string),
+		selfClosing: selfClosing,
+	}
+	for _, match := range attributeRegex.FindAllStringSubmatch(tag[nameEnd:], -1) {
+		value := match[2]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		tok.attrs[strings.ToLower(match[1])] = html.UnescapeString(value)
+	}
+	return tok
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
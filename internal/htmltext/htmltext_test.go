@@ -0,0 +1,250 @@
+package htmltext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		width int
+		want  string
+	}{
+		{
+			name:  "plain text",
+			html:  "Just some plain text",
+			width: 80,
+			want:  "Just some plain text",
+		},
+		{
+			name:  "whitespace is collapsed",
+			html:  "  lots   of\n\twhitespace  ",
+			width: 80,
+			want:  "lots of\nwhitespace",
+		},
+		{
+			name:  "paragraphs",
+			html:  "<p>First paragraph</p><p>Second paragraph</p>",
+			width: 80,
+			want:  "First paragraph\n\nSecond paragraph",
+		},
+		{
+			name:  "wrapping",
+			html:  "<p>The quick brown fox jumps over the lazy dog</p>",
+			width: 20,
+			want:  "The quick brown fox\njumps over the lazy\ndog",
+		},
+		{
+			name:  "no wrapping",
+			html:  "<p>The quick brown fox jumps over the lazy dog</p>",
+			width: 0,
+			want:  "The quick brown fox jumps over the lazy dog",
+		},
+		{
+			name:  "long words get a line of their own",
+			html:  "<p>A supercalifragilisticexpialidocious word</p>",
+			width: 10,
+			want:  "A\nsupercalifragilisticexpialidocious\nword",
+		},
+		{
+			name:  "line breaks",
+			html:  "Line one<br>Line two<br/>Line three",
+			width: 80,
+			want:  "Line one\nLine two\nLine three",
+		},
+		{
+			name:  "headings",
+			html:  "<h1>Title</h1><h3>Subtitle</h3><p>Body</p>",
+			width: 80,
+			want:  "# Title\n\n### Subtitle\n\nBody",
+		},
+		{
+			name:  "nested unordered lists",
+			html:  "<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li><li>Three</li></ul>",
+			width: 80,
+			want:  "• One\n• Two\n  ◦ Nested\n• Three",
+		},
+		{
+			name:  "ordered list with start",
+			html:  `<ol start="3"><li>Three</li><li>Four</li></ol>`,
+			width: 80,
+			want:  "3. Three\n4. Four",
+		},
+		{
+			name:  "wrapped list items are indented",
+			html:  "<ul><li>A long list item that has to be wrapped onto a second line</li></ul>",
+			width: 30,
+			want:  "• A long list item that has to\n  be wrapped onto a second\n  line",
+		},
+		{
+			name:  "blockquote",
+			html:  "<blockquote><p>Quoted one</p><p>Quoted two</p></blockquote><p>After</p>",
+			width: 80,
+			want:  "> Quoted one\n>\n> Quoted two\n\nAfter",
+		},
+		{
+			name:  "preformatted text is kept as is",
+			html:  "<p>Before</p><pre>func main() {\n\tfmt.Println(\"hi\")\n}</pre><p>After</p>",
+			width: 80,
+			want:  "Before\n\n    func main() {\n    \tfmt.Println(\"hi\")\n    }\n\nAfter",
+		},
+		{
+			name:  "links become footnotes",
+			html:  `<p>See <a href="https://example.com/a">this</a> and <a href="https://example.com/b">that</a> and <a href="https://example.com/a">this again</a>.</p>`,
+			width: 80,
+			want:  "See this[1] and that[2] and this again[1].\n\n[1] https://example.com/a\n[2] https://example.com/b",
+		},
+		{
+			name:  "links without footnotes",
+			html:  `<p><a href="https://example.com">https://example.com</a> <a href="#top">top</a> <a href="javascript:void(0)">js</a></p>`,
+			width: 80,
+			want:  "https://example.com top js",
+		},
+		{
+			name:  "escaped link href",
+			html:  `<a href="https://example.com/?a=1&amp;b=2" title="x > y">link</a>`,
+			width: 80,
+			want:  "link[1]\n\n[1] https://example.com/?a=1&b=2",
+		},
+		{
+			name:  "scripts and styles are stripped",
+			html:  "<p>Before</p><script>document.write('<p>hidden</p>')</script><style>p { color: red }</style><p>After</p>",
+			width: 80,
+			want:  "Before\n\nAfter",
+		},
+		{
+			name:  "hidden elements are stripped",
+			html:  "<p>before</p><svg><text>hidden</text></svg><p>after</p>",
+			width: 80,
+			want:  "before\n\nafter",
+		},
+		{
+			name:  "self-closing hidden element",
+			html:  `<p>before</p><svg viewBox="0 0 1 1"/><p>after</p>`,
+			width: 80,
+			want:  "before\n\nafter",
+		},
+		{
+			name:  "self-closing iframe and select",
+			html:  `<p>before</p><iframe src="https://example.com"/><select/><p>after</p>`,
+			width: 80,
+			want:  "before\n\nafter",
+		},
+		{
+			name:  "self-closing script",
+			html:  `<p>before</p><script src="x.js"/><p>after</p>`,
+			width: 80,
+			want:  "before\n\nafter",
+		},
+		{
+			name:  "entities",
+			html:  "<p>Tom &amp; Jerry &lt;3 &quot;cartoons&quot; &#8212; &eacute;</p>",
+			width: 80,
+			want:  "Tom & Jerry <3 \"cartoons\" — é",
+		},
+		{
+			name:  "images",
+			html:  `<p><img src="a.png" alt="A cat"> <img src="b.png"></p>`,
+			width: 80,
+			want:  "[image: A cat]",
+		},
+		{
+			name:  "stray angle brackets",
+			html:  "<p>a &lt; b</p><p>1 < 2 and 3 > 2</p>",
+			width: 80,
+			want:  "a < b\n\n1 < 2 and 3 > 2",
+		},
+		{
+			name:  "comments and doctypes",
+			html:  "<!-- comment --><!DOCTYPE html><p>Text</p>",
+			width: 80,
+			want:  "Text",
+		},
+		{
+			name:  "tables",
+			html:  "<table><tr><td>a</td><td>b</td></tr><tr><td>c</td><td>d</td></tr></table>",
+			width: 80,
+			want:  "a b\nc d",
+		},
+		{
+			name:  "horizontal rule",
+			html:  "<p>one</p><hr><p>two</p>",
+			width: 80,
+			want:  "one\n\n---\n\ntwo",
+		},
+		{
+			name:  "uppercase tags",
+			html:  `<P CLASS="x">Upper <B>case</B></P>`,
+			width: 80,
+			want:  "Upper case",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.html, tt.width)
+			if got != tt.want {
+				t.Errorf("Render(%q, %d) =\n%s\nwant\n%s", tt.html, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStartTag(t *testing.T) {
+	tests := []struct {
+		tag         string
+		name        string
+		attrs       map[string]string
+		selfClosing bool
+	}{
+		{"p", "p", map[string]string{}, false},
+		{"br/", "br", map[string]string{}, true},
+		{`svg viewBox="0 0 1 1" /`, "svg", map[string]string{"viewbox": "0 0 1 1"}, true},
+		{`A HREF='https://example.com' target=_blank`, "a", map[string]string{"href": "https://example.com", "target": "_blank"}, false},
+		{`input disabled`, "input", map[string]string{"disabled": ""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			tok := parseStartTag(tt.tag)
+			if tok.data != tt.name {
+				t.Errorf("name = %q, want %q", tok.data, tt.name)
+			}
+			if tok.selfClosing != tt.selfClosing {
+				t.Errorf("selfClosing = %t, want %t", tok.selfClosing, tt.selfClosing)
+			}
+			if len(tok.attrs) != len(tt.attrs) {
+				t.Errorf("attrs = %v, want %v", tok.attrs, tt.attrs)
+			}
+			for name, value := range tt.attrs {
+				if tok.attrs[name] != value {
+					t.Errorf("attrs[%q] = %q, want %q", name, tok.attrs[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"", 20, []string{""}},
+		{"A <b>title</b> with &amp; markup", 80, []string{"A <b>title</b> with &amp; markup"}},
+		{"The quick brown fox jumps over the lazy dog", 20, []string{"The quick brown fox", "jumps over the lazy", "dog"}},
+		{"  Extra\n  whitespace  ", 0, []string{"Extra whitespace"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := Wrap(tt.text, tt.width)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") || len(got) != len(tt.want) {
+				t.Errorf("Wrap(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
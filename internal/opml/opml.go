@@ -0,0 +1,155 @@
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline is either a feed subscription, when XMLURL is set, or a folder containing other
+// outlines
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a feed subscription found in an OPML document along with the folder it was found in.
+// Nested folders are separated by slashes.
+type Feed struct {
+	Title  string
+	URL    string
+	Folder string
+}
+
+// Parse decodes an OPML document
+func Parse(r io.Reader) (*OPML, error) {
+	var doc OPML
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling OPML: %w", err)
+	}
+	return &doc, nil
+}
+
+// Feeds returns all of the feed subscriptions in the document, including those in nested
+// folders
+func (doc *OPML) Feeds() []Feed {
+	return collectFeeds(doc.Body.Outlines, nil)
+}
+
+func collectFeeds(outlines []Outline, folders []string) []Feed {
+	var feeds []Feed
+	for _, outline := range outlines {
+		title := strings.TrimSpace(outline.Title)
+		if title == "" {
+			title = strings.TrimSpace(outline.Text)
+		}
+
+		if outline.XMLURL != "" {
+			feeds = append(feeds, Feed{
+				Title:  title,
+				URL:    strings.TrimSpace(outline.XMLURL),
+				Folder: strings.Join(folders, "/"),
+			})
+		}
+
+		if len(outline.Outlines) > 0 {
+			// Copy the folder path so that sibling folders don't share a backing array
+			subFolders := append(append([]string{}, folders...), title)
+			feeds = append(feeds, collectFeeds(outline.Outlines, subFolders)...)
+		}
+	}
+	return feeds
+}
+
+// New builds an OPML 2.0 document from a list of feeds. Feeds in folders are nested inside
+// outlines for each of their folders.
+func New(title string, dateCreated time.Time, feeds []Feed) *OPML {
+	doc := OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: dateCreated.Format(time.RFC1123Z),
+		},
+	}
+
+	for _, feed := range feeds {
+		var folders []string
+		if feed.Folder != "" {
+			folders = strings.Split(feed.Folder, "/")
+		}
+		outline := Outline{
+			Text:   feed.Title,
+			Title:  feed.Title,
+			Type:   "rss",
+			XMLURL: feed.URL,
+		}
+		doc.Body.Outlines = insertOutline(doc.Body.Outlines, folders, outline)
+	}
+
+	return &doc
+}
+
+// insertOutline adds an outline to the given folder, creating any folders that don't exist yet
+func insertOutline(outlines []Outline, folders []string, outline Outline) []Outline {
+	if len(folders) == 0 {
+		return append(outlines, outline)
+	}
+
+	for i := range outlines {
+		if outlines[i].XMLURL == "" && outlines[i].Text == folders[0] {
+			outlines[i].Outlines = insertOutline(outlines[i].Outlines, folders[1:], outline)
+			return outlines
+		}
+	}
+
+	folder := Outline{
+		Text:  folders[0],
+		Title: folders[0],
+	}
+	folder.Outlines = insertOutline(nil, folders[1:], outline)
+	return append(outlines, folder)
+}
+
+// Write encodes the document as indented XML
+func (doc *OPML) Write(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("Error writing OPML: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return fmt.Errorf("Error marshaling OPML: %w", err)
+	}
+
+	_, err = io.WriteString(w, "\n")
+	if err != nil {
+		return fmt.Errorf("Error writing OPML: %w", err)
+	}
+	return nil
+}
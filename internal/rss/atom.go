@@ -0,0 +1,107 @@
+package rss
+
+import (
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type AtomFeed struct {
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Link     []AtomLink  `xml:"link"`
+	Entry    []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Link      []AtomLink `xml:"link"`
+	Summary   AtomText   `xml:"summary"`
+	Content   AtomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// AtomText holds an Atom text construct. Plain text and escaped HTML are stored as character
+// data while XHTML content is stored as child elements, so we keep both around.
+type AtomText struct {
+	Type     string `xml:"type,attr"`
+	CharData string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.InnerXML)
+	}
+	return strings.TrimSpace(t.CharData)
+}
+
+// alternateLink returns the link that points to the HTML version of the feed or entry. Links
+// without a `rel` attribute are treated as alternate links as per the Atom spec.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// enclosureLinks returns the links that point to media files attached to an entry
+func enclosureLinks(links []AtomLink) []RSSEnclosure {
+	var enclosures []RSSEnclosure
+	for _, link := range links {
+		if link.Rel == "enclosure" {
+			enclosures = append(enclosures, RSSEnclosure{
+				URL:    link.Href,
+				Type:   link.Type,
+				Length: link.Length,
+			})
+		}
+	}
+	return enclosures
+}
+
+// toRSS converts an Atom feed, using the alternate link of each entry as its link
+func (atom *AtomFeed) toRSS() *RSSFeed {
+	var rssFeed RSSFeed
+	rssFeed.Channel.Title = atom.Title
+	rssFeed.Channel.Link = alternateLink(atom.Link)
+	rssFeed.Channel.Description = atom.Subtitle
+
+	for _, entry := range atom.Entry {
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, RSSItem{
+			GUID:        entry.ID,
+			Title:       entry.Title,
+			Link:        alternateLink(entry.Link),
+			Description: description,
+			PubDate:     pubDate,
+			Content:     entry.Content.String(),
+			Enclosures:  enclosureLinks(entry.Link),
+		})
+	}
+
+	return &rssFeed
+}
@@ -0,0 +1,214 @@
+package rss
+
+import (
+	"testing"
+)
+
+func TestParseFeedAtom(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want RSSItem
+	}{
+		{
+			name: "alternate link",
+			feed: `<entry>
+				<id>urn:uuid:1</id>
+				<title>Post</title>
+				<link rel="self" href="https://example.com/self"/>
+				<link rel="alternate" type="text/html" href="https://example.com/post"/>
+				<published>2024-01-02T03:04:05Z</published>
+			</entry>`,
+			want: RSSItem{
+				GUID:    "urn:uuid:1",
+				Title:   "Post",
+				Link:    "https://example.com/post",
+				PubDate: "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "link without rel",
+			feed: `<entry>
+				<id>urn:uuid:2</id>
+				<title>Post</title>
+				<link rel="replies" href="https://example.com/comments"/>
+				<link href="https://example.com/post"/>
+				<published>2024-01-02T03:04:05Z</published>
+			</entry>`,
+			want: RSSItem{
+				GUID:    "urn:uuid:2",
+				Title:   "Post",
+				Link:    "https://example.com/post",
+				PubDate: "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "no alternate link",
+			feed: `<entry>
+				<id>urn:uuid:3</id>
+				<title>Post</title>
+				<link rel="related" href="https://example.com/related"/>
+				<published>2024-01-02T03:04:05Z</published>
+			</entry>`,
+			want: RSSItem{
+				GUID:    "urn:uuid:3",
+				Title:   "Post",
+				Link:    "https://example.com/related",
+				PubDate: "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "summary and content",
+			feed: `<entry>
+				<id>urn:uuid:4</id>
+				<title>Post</title>
+				<summary>Short summary</summary>
+				<content type="html">&lt;p&gt;Full content&lt;/p&gt;</content>
+				<published>2024-01-02T03:04:05Z</published>
+			</entry>`,
+			want: RSSItem{
+				GUID:        "urn:uuid:4",
+				Title:       "Post",
+				Description: "Short summary",
+				Content:     "<p>Full content</p>",
+				PubDate:     "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "content without summary",
+			feed: `<entry>
+				<id>urn:uuid:5</id>
+				<title>Post</title>
+				<content type="html">&lt;p&gt;Full content&lt;/p&gt;</content>
+				<published>2024-01-02T03:04:05Z</published>
+			</entry>`,
+			want: RSSItem{
+				GUID:        "urn:uuid:5",
+				Title:       "Post",
+				Description: "<p>Full content</p>",
+				Content:     "<p>Full content</p>",
+				PubDate:     "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "xhtml content",
+			feed: `<entry>
+				<id>urn:uuid:6</id>
+				<title>Post</title>
+				<content type="xhtml">
+					<div xmlns="http://www.w3.org/1999/xhtml"><p>Full <b>content</b></p></div>
+				</content>
+				<published>2024-01-02T03:04:05Z</published>
+			</entry>`,
+			want: RSSItem{
+				GUID:        "urn:uuid:6",
+				Title:       "Post",
+				Description: `<div xmlns="http://www.w3.org/1999/xhtml"><p>Full <b>content</b></p></div>`,
+				Content:     `<div xmlns="http://www.w3.org/1999/xhtml"><p>Full <b>content</b></p></div>`,
+				PubDate:     "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "published and updated",
+			feed: `<entry>
+				<id>urn:uuid:7</id>
+				<title>Post</title>
+				<published>2024-01-02T03:04:05Z</published>
+				<updated>2024-02-03T04:05:06Z</updated>
+			</entry>`,
+			want: RSSItem{
+				GUID:    "urn:uuid:7",
+				Title:   "Post",
+				PubDate: "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "updated without published",
+			feed: `<entry>
+				<id>urn:uuid:8</id>
+				<title>Post</title>
+				<updated>2024-02-03T04:05:06Z</updated>
+			</entry>`,
+			want: RSSItem{
+				GUID:    "urn:uuid:8",
+				Title:   "Post",
+				PubDate: "2024-02-03T04:05:06Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawFeed := `<?xml version="1.0" encoding="utf-8"?>
+				<feed xmlns="http://www.w3.org/2005/Atom">
+					<title>Example</title>
+					<link href="https://example.com/"/>
+					` + tt.feed + `
+				</feed>`
+			feed, err := parseFeed([]byte(rawFeed), "application/atom+xml")
+			if err != nil {
+				t.Fatalf("parseFeed() error = %v", err)
+			}
+
+			if feed.Channel.Title != "Example" {
+				t.Errorf("Channel.Title = %q, want %q", feed.Channel.Title, "Example")
+			}
+			if feed.Channel.Link != "https://example.com/" {
+				t.Errorf("Channel.Link = %q, want %q", feed.Channel.Link, "https://example.com/")
+			}
+			if len(feed.Channel.Item) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Channel.Item))
+			}
+
+			got := feed.Channel.Item[0]
+			if got.GUID != tt.want.GUID {
+				t.Errorf("GUID = %q, want %q", got.GUID, tt.want.GUID)
+			}
+			if got.Title != tt.want.Title {
+				t.Errorf("Title = %q, want %q", got.Title, tt.want.Title)
+			}
+			if got.Link != tt.want.Link {
+				t.Errorf("Link = %q, want %q", got.Link, tt.want.Link)
+			}
+			if got.Description != tt.want.Description {
+				t.Errorf("Description = %q, want %q", got.Description, tt.want.Description)
+			}
+			if got.Content != tt.want.Content {
+				t.Errorf("Content = %q, want %q", got.Content, tt.want.Content)
+			}
+			if got.PubDate != tt.want.PubDate {
+				t.Errorf("PubDate = %q, want %q", got.PubDate, tt.want.PubDate)
+			}
+		})
+	}
+}
+
+func TestParseFeedAtomEnclosures(t *testing.T) {
+	rawFeed := `<?xml version="1.0" encoding="utf-8"?>
+		<feed xmlns="http://www.w3.org/2005/Atom">
+			<title>Example</title>
+			<entry>
+				<id>urn:uuid:1</id>
+				<title>Episode</title>
+				<link href="https://example.com/episode"/>
+				<link rel="enclosure" type="audio/mpeg" length="1234" href="https://example.com/episode.mp3"/>
+				<updated>2024-01-02T03:04:05Z</updated>
+			</entry>
+		</feed>`
+	feed, err := parseFeed([]byte(rawFeed), "")
+	if err != nil {
+		t.Fatalf("parseFeed() error = %v", err)
+	}
+	if len(feed.Channel.Item) != 1 {
+		t.Fatalf("got %d items, want 1", len(feed.Channel.Item))
+	}
+
+	item := feed.Channel.Item[0]
+	if item.Link != "https://example.com/episode" {
+		t.Errorf("Link = %q, want %q", item.Link, "https://example.com/episode")
+	}
+	want := RSSEnclosure{URL: "https://example.com/episode.mp3", Type: "audio/mpeg", Length: "1234"}
+	if len(item.Enclosures) != 1 || item.Enclosures[0] != want {
+		t.Errorf("Enclosures = %+v, want [%+v]", item.Enclosures, want)
+	}
+}
@@ -0,0 +1,175 @@
+package rss
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateLayouts contains the layouts tried by ParseDate, roughly ordered from most to least common.
+// Leading weekdays and named time zones are normalized before these are tried, and `2` and `15`
+// also match single-digit days and hours.
+var dateLayouts = []string{
+	// RFC 822/1123 and variants
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2 January 2006 15:04:05",
+	"2 Jan 2006",
+	"2 January 2006",
+
+	// RFC 850 and its four-digit year variant
+	"2-Jan-06 15:04:05 -0700",
+	"2-Jan-2006 15:04:05 -0700",
+
+	// RFC 3339/ISO 8601 and variants
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05 -0700",
+	"2006-01-02T15:04:05.999999999 -0700",
+	"2006-01-02T15:04:05.999999999-0700",
+	"2006-01-02T15:04-07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"20060102T150405Z0700",
+	"20060102",
+
+	// US style and output of common libraries
+	"Jan 2 15:04:05 2006",
+	"Jan 2 15:04:05 -0700 2006",
+	"Jan 2, 2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04 -0700",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006 3:04 PM",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"January 2, 2006 15:04:05 -0700",
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006",
+	"January 2 2006",
+	"01/02/2006 15:04:05",
+	"01/02/2006",
+}
+
+// zoneOffsets maps commonly used time zone abbreviations to their UTC offsets. Go only knows the
+// offsets of UTC and the local time zone so these are replaced with numeric offsets before
+// parsing.
+var zoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"WET":  "+0000",
+	"WEST": "+0100",
+	"BST":  "+0100",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"MET":  "+0100",
+	"MEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"MSK":  "+0300",
+	"IST":  "+0530",
+	"SGT":  "+0800",
+	"HKT":  "+0800",
+	"AWST": "+0800",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"ACST": "+0930",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+	"AST":  "-0400",
+	"ADT":  "-0300",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+}
+
+var weekdayPrefixes = []string{
+	"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+	"mon", "tues", "tue", "wed", "thurs", "thur", "thu", "fri", "sat", "sun",
+}
+
+// ParseDate parses the publication dates found in RSS, Atom and JSON feeds. Besides the formats
+// required by the specs it also tries to make sense of the many malformed dates found in the
+// wild, such as dates with single-digit days, missing or incorrect weekdays, and named time
+// zones. Dates without a time zone are assumed to be in UTC.
+func ParseDate(dateStr string) (time.Time, error) {
+	normalized := normalizeDate(dateStr)
+	if normalized == "" {
+		return time.Time{}, fmt.Errorf("Empty date string")
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, normalized)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Unrecognized date format: %q", dateStr)
+}
+
+// normalizeDate collapses whitespace, strips leading weekdays and replaces named time zones with
+// numeric offsets so that fewer layouts need to be tried
+func normalizeDate(dateStr string) string {
+	fields := strings.Fields(dateStr)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	// Weekdays don't add any information and are often wrong or misspelled. Some feeds leave out
+	// the space after the comma as in "Mon,02 Jan 2006" so split those off first.
+	if before, after, ok := strings.Cut(fields[0], ","); ok && before != "" && after != "" {
+		fields = append([]string{before, after}, fields[1:]...)
+	}
+	first := strings.ToLower(strings.TrimRight(fields[0], ",."))
+	for _, weekday := range weekdayPrefixes {
+		if first == weekday {
+			fields = fields[1:]
+			break
+		}
+	}
+	if len(fields) > 0 && fields[0] == "," {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	// Replace a trailing zone name, which may also be wrapped in parentheses as in
+	// "-0400 (EDT)", in which case the numeric offset is kept instead
+	last := strings.Trim(fields[len(fields)-1], "()")
+	if offset, ok := zoneOffsets[strings.ToUpper(last)]; ok && len(fields) > 1 {
+		if strings.HasPrefix(fields[len(fields)-1], "(") {
+			fields = fields[:len(fields)-1]
+		} else {
+			fields[len(fields)-1] = offset
+		}
+	}
+
+	return strings.Join(fields, " ")
+}
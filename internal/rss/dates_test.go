@@ -0,0 +1,110 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+	want := utc(2006, time.January, 2, 15, 4, 5)
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		// RFC 822/1123 and variants
+		{"Mon, 02 Jan 2006 15:04:05 +0000", want},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", want},
+		{"Mon, 02 Jan 2006 15:04:05 UT", want},
+		{"Mon, 02 Jan 2006 15:04:05 Z", want},
+		{"Mon, 02 Jan 2006 08:04:05 -0700", want},
+		{"Mon, 02 Jan 2006 08:04:05 -07:00", want},
+		{"Mon, 02 Jan 2006 10:04:05 EST", want},
+		{"Mon, 02 Jan 2006 11:04:05 -0400 (EDT)", want},
+		{"Mon, 02 Jan 2006 16:04:05 CET", want},
+		{"Mon, 2 Jan 2006 15:04:05 +0000", want},
+		{"Mon,02 Jan 2006 15:04:05 +0000", want},
+		{"Mon , 02 Jan 2006 15:04:05 +0000", want},
+		{"Monday, 02 Jan 2006 15:04:05 +0000", want},
+		{"Tue, 02 Jan 2006 15:04:05 +0000", want},
+		{"Thurs, 02 Jan 2006 15:04:05 +0000", want},
+		{"mon, 02 jan 2006 15:04:05 gmt", want},
+		{"  Mon,  02 Jan  2006\n15:04:05 +0000  ", want},
+		{"02 Jan 2006 15:04:05 +0000", want},
+		{"02 Jan 2006 15:04:05", want},
+		{"Mon, 02 Jan 2006 15:04 +0000", utc(2006, time.January, 2, 15, 4, 0)},
+		{"Mon, 02 Jan 06 15:04:05 +0000", want},
+		{"Mon, 02 January 2006 15:04:05 +0000", want},
+		{"02 January 2006", utc(2006, time.January, 2, 0, 0, 0)},
+		{"2 Jan 2006", utc(2006, time.January, 2, 0, 0, 0)},
+
+		// RFC 850
+		{"Monday, 02-Jan-06 15:04:05 GMT", want},
+		{"Monday, 02-Jan-06 08:04:05 MST", want},
+		{"Mon, 02-Jan-2006 15:04:05 GMT", want},
+
+		// RFC 3339/ISO 8601 and variants
+		{"2006-01-02T15:04:05Z", want},
+		{"2006-01-02T15:04:05.123Z", want.Add(123 * time.Millisecond)},
+		{"2006-01-02T08:04:05-07:00", want},
+		{"2006-01-02T08:04:05-0700", want},
+		{"2006-01-02T15:04:05 Z", want},
+		{"2006-01-02T15:04:05.5 Z", want.Add(500 * time.Millisecond)},
+		{"2006-01-02T08:04-07:00", utc(2006, time.January, 2, 15, 4, 0)},
+		{"2006-01-02T15:04:05", want},
+		{"2006-01-02T15:04", utc(2006, time.January, 2, 15, 4, 0)},
+		{"2006-01-02 15:04:05 +0000", want},
+		{"2006-01-02 08:04:05-07:00", want},
+		{"2006-01-02 15:04:05", want},
+		{"2006-01-02", utc(2006, time.January, 2, 0, 0, 0)},
+		{"20060102T150405Z", want},
+		{"20060102", utc(2006, time.January, 2, 0, 0, 0)},
+
+		// US style and output of common libraries
+		{"Mon Jan 2 15:04:05 2006", want},
+		{"Mon Jan 2 08:04:05 -0700 2006", want},
+		{"Jan 2, 2006 15:04:05 +0000", want},
+		{"Jan 2, 2006 3:04 PM", utc(2006, time.January, 2, 15, 4, 0)},
+		{"Jan 2, 2006", utc(2006, time.January, 2, 0, 0, 0)},
+		{"Jan 2 2024", utc(2024, time.January, 2, 0, 0, 0)},
+		{"January 2, 2006 3:04 PM", utc(2006, time.January, 2, 15, 4, 0)},
+		{"January 2 2006", utc(2006, time.January, 2, 0, 0, 0)},
+		{"01/02/2006 15:04:05", want},
+		{"01/02/2006", utc(2006, time.January, 2, 0, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDate(tt.input)
+			if err != nil {
+				t.Fatalf("ParseDate(%q) error = %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %s, want %s", tt.input, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"Mon,",
+		"yesterday",
+		"not a date",
+		"2006-13-45",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := ParseDate(input)
+			if err == nil {
+				t.Errorf("ParseDate(%q) = %s, want error", input, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// FeedLink is a feed found while looking for feeds on a web page
+type FeedLink struct {
+	URL   string
+	Title string
+	Type  string
+}
+
+var (
+	linkTagRegex   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	attributeRegex = regexp.MustCompile(`(?is)([a-z-]+)\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+	feedMimeTypes  = []string{
+		"application/rss+xml",
+		"application/atom+xml",
+		"application/feed+json",
+		"application/rdf+xml",
+	}
+
+	// Paths that are tried when a page doesn't advertise any feeds
+	commonFeedPaths = []string{
+		"/feed",
+		"/feed/",
+		"/rss",
+		"/rss.xml",
+		"/atom.xml",
+		"/feed.xml",
+		"/feed.json",
+		"/index.xml",
+	}
+)
+
+// DiscoverFeeds looks for feeds at the given URL. If the URL already points to a feed then it's
+// returned as the only result. Otherwise the URL is assumed to be a web page and the feeds it
+// advertises with `<link rel="alternate">` tags are returned. If the page doesn't advertise any
+// feeds then a few common feed paths are tried instead.
+func DiscoverFeeds(ctx context.Context, pageURL string) ([]FeedLink, error) {
+	body, contentType, err := get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := parseFeed(body, contentType); err == nil {
+		return []FeedLink{{URL: pageURL, Type: contentType}}, nil
+	}
+
+	baseURL, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing page URL: %w", err)
+	}
+
+	feeds := feedLinksFromHTML(string(body), baseURL)
+	if len(feeds) > 0 {
+		return feeds, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		candidateURL := baseURL.ResolveReference(&url.URL{Path: path}).String()
+		body, contentType, err := get(ctx, candidateURL)
+		if err != nil {
+			continue
+		}
+		if _, err := parseFeed(body, contentType); err == nil {
+			feeds = append(feeds, FeedLink{URL: candidateURL, Type: contentType})
+		}
+	}
+
+	return feeds, nil
+}
+
+// feedLinksFromHTML returns the feeds advertised in the `<link>` tags of an HTML page. Relative
+// links are resolved against the URL of the page.
+func feedLinksFromHTML(page string, baseURL *url.URL) []FeedLink {
+	var feeds []FeedLink
+	seen := make(map[string]bool)
+	for _, tag := range linkTagRegex.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, match := range attributeRegex.FindAllStringSubmatch(tag, -1) {
+			value := strings.Trim(match[2], `"'`)
+			attrs[strings.ToLower(match[1])] = html.UnescapeString(value)
+		}
+
+		if !hasToken(attrs["rel"], "alternate") || !isFeedMimeType(attrs["type"]) {
+			continue
+		}
+
+		href, err := baseURL.Parse(strings.TrimSpace(attrs["href"]))
+		if err != nil || attrs["href"] == "" || seen[href.String()] {
+			continue
+		}
+		seen[href.String()] = true
+
+		feeds = append(feeds, FeedLink{
+			URL:   href.String(),
+			Title: attrs["title"],
+			Type:  attrs["type"],
+		})
+	}
+	return feeds
+}
+
+func hasToken(list, token string) bool {
+	for _, t := range strings.Fields(strings.ToLower(list)) {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+func isFeedMimeType(mimeType string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	for _, feedType := range feedMimeTypes {
+		if strings.HasPrefix(mimeType, feedType) {
+			return true
+		}
+	}
+	return false
+}
+
+// get downloads the given URL and returns the body along with its content type
+func get(ctx context.Context, rawURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("Error creating request: %w", err)
+	}
+
+	req.Header.Add("User-Agent", "gator")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("Error sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, "", fmt.Errorf("Unexpected response status: %s", res.Status)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("Error reading response: %w", err)
+	}
+
+	return body, res.Header.Get("Content-Type"), nil
+}
@@ -0,0 +1,111 @@
+package rss
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+
+	Attachments []JSONFeedAttachment `json:"attachments"`
+}
+
+type JSONFeedAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	SizeInBytes       int64   `json:"size_in_bytes"`
+	DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+// isJSONFeed reports whether a feed should be decoded as a JSON feed. Some servers send JSON
+// feeds as `text/plain`, so we also check whether the body looks like a JSON object.
+func isJSONFeed(rawFeed []byte, contentType string) bool {
+	if strings.Contains(contentType, "json") {
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(rawFeed), []byte("{"))
+}
+
+// toRSS converts a JSON feed, using the ID of items without a URL as their link when it's a URL
+func (jf *JSONFeed) toRSS() *RSSFeed {
+	var rssFeed RSSFeed
+	rssFeed.Channel.Title = jf.Title
+	rssFeed.Channel.Link = jf.HomePageURL
+	rssFeed.Channel.Description = jf.Description
+
+	for _, item := range jf.Items {
+		description := item.Summary
+		if description == "" {
+			description = item.ContentHTML
+		}
+		if description == "" {
+			description = item.ContentText
+		}
+
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+
+		// Items without a URL are allowed by the spec but the ID is often a permalink
+		link := item.URL
+		if link == "" && strings.HasPrefix(item.ID, "http") {
+			link = item.ID
+		}
+
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+
+		// JSON Feed has no item-level duration so use the duration of the first attachment
+		var enclosures []RSSEnclosure
+		var duration string
+		for _, attachment := range item.Attachments {
+			enclosure := RSSEnclosure{
+				URL:  attachment.URL,
+				Type: attachment.MimeType,
+			}
+			if attachment.SizeInBytes > 0 {
+				enclosure.Length = strconv.FormatInt(attachment.SizeInBytes, 10)
+			}
+			if duration == "" && attachment.DurationInSeconds > 0 {
+				duration = strconv.Itoa(int(attachment.DurationInSeconds))
+			}
+			enclosures = append(enclosures, enclosure)
+		}
+
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, RSSItem{
+			GUID:        item.ID,
+			Title:       item.Title,
+			Link:        link,
+			Description: description,
+			PubDate:     pubDate,
+			Content:     content,
+
+			Enclosures:     enclosures,
+			ITunesDuration: duration,
+		})
+	}
+
+	return &rssFeed
+}
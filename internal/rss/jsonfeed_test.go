@@ -0,0 +1,270 @@
+package rss
+
+import (
+	"testing"
+)
+
+func TestParseFeedJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want RSSItem
+	}{
+		{
+			name: "html content",
+			feed: `{
+				"id": "1",
+				"url": "https://example.com/post",
+				"title": "Post",
+				"content_html": "<p>Full content</p>",
+				"date_published": "2024-01-02T03:04:05Z"
+			}`,
+			want: RSSItem{
+				GUID:        "1",
+				Title:       "Post",
+				Link:        "https://example.com/post",
+				Description: "<p>Full content</p>",
+				Content:     "<p>Full content</p>",
+				PubDate:     "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "summary and content",
+			feed: `{
+				"id": "2",
+				"url": "https://example.com/post",
+				"title": "Post",
+				"summary": "Short summary",
+				"content_html": "<p>Full content</p>",
+				"date_published": "2024-01-02T03:04:05Z"
+			}`,
+			want: RSSItem{
+				GUID:        "2",
+				Title:       "Post",
+				Link:        "https://example.com/post",
+				Description: "Short summary",
+				Content:     "<p>Full content</p>",
+				PubDate:     "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "text content",
+			feed: `{
+				"id": "3",
+				"url": "https://example.com/post",
+				"title": "Post",
+				"content_text": "Plain content",
+				"date_published": "2024-01-02T03:04:05Z"
+			}`,
+			want: RSSItem{
+				GUID:        "3",
+				Title:       "Post",
+				Link:        "https://example.com/post",
+				Description: "Plain content",
+				Content:     "Plain content",
+				PubDate:     "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "html content preferred over text",
+			feed: `{
+				"id": "4",
+				"url": "https://example.com/post",
+				"title": "Post",
+				"content_html": "<p>Full content</p>",
+				"content_text": "Full content",
+				"date_published": "2024-01-02T03:04:05Z"
+			}`,
+			want: RSSItem{
+				GUID:        "4",
+				Title:       "Post",
+				Link:        "https://example.com/post",
+				Description: "<p>Full content</p>",
+				Content:     "<p>Full content</p>",
+				PubDate:     "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "id used as link",
+			feed: `{
+				"id": "https://example.com/post",
+				"title": "Post",
+				"date_published": "2024-01-02T03:04:05Z"
+			}`,
+			want: RSSItem{
+				GUID:    "https://example.com/post",
+				Title:   "Post",
+				Link:    "https://example.com/post",
+				PubDate: "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "id that isn't a URL",
+			feed: `{
+				"id": "tag:example.com,2024:5",
+				"title": "Post",
+				"date_published": "2024-01-02T03:04:05Z"
+			}`,
+			want: RSSItem{
+				GUID:    "tag:example.com,2024:5",
+				Title:   "Post",
+				PubDate: "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "url preferred over id",
+			feed: `{
+				"id": "https://example.com/?p=6",
+				"url": "https://example.com/post",
+				"title": "Post",
+				"date_published": "2024-01-02T03:04:05Z"
+			}`,
+			want: RSSItem{
+				GUID:    "https://example.com/?p=6",
+				Title:   "Post",
+				Link:    "https://example.com/post",
+				PubDate: "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "modified without published",
+			feed: `{
+				"id": "7",
+				"title": "Post",
+				"date_modified": "2024-02-03T04:05:06Z"
+			}`,
+			want: RSSItem{
+				GUID:    "7",
+				Title:   "Post",
+				PubDate: "2024-02-03T04:05:06Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawFeed := `{
+				"version": "https://jsonfeed.org/version/1.1",
+				"title": "Example",
+				"home_page_url": "https://example.com/",
+				"items": [` + tt.feed + `]
+			}`
+			feed, err := parseFeed([]byte(rawFeed), "application/feed+json")
+			if err != nil {
+				t.Fatalf("parseFeed() error = %v", err)
+			}
+
+			if feed.Channel.Title != "Example" {
+				t.Errorf("Channel.Title = %q, want %q", feed.Channel.Title, "Example")
+			}
+			if feed.Channel.Link != "https://example.com/" {
+				t.Errorf("Channel.Link = %q, want %q", feed.Channel.Link, "https://example.com/")
+			}
+			if len(feed.Channel.Item) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Channel.Item))
+			}
+
+			got := feed.Channel.Item[0]
+			if got.GUID != tt.want.GUID {
+				t.Errorf("GUID = %q, want %q", got.GUID, tt.want.GUID)
+			}
+			if got.Title != tt.want.Title {
+				t.Errorf("Title = %q, want %q", got.Title, tt.want.Title)
+			}
+			if got.Link != tt.want.Link {
+				t.Errorf("Link = %q, want %q", got.Link, tt.want.Link)
+			}
+			if got.Description != tt.want.Description {
+				t.Errorf("Description = %q, want %q", got.Description, tt.want.Description)
+			}
+			if got.Content != tt.want.Content {
+				t.Errorf("Content = %q, want %q", got.Content, tt.want.Content)
+			}
+			if got.PubDate != tt.want.PubDate {
+				t.Errorf("PubDate = %q, want %q", got.PubDate, tt.want.PubDate)
+			}
+		})
+	}
+}
+
+func TestParseFeedJSONAttachments(t *testing.T) {
+	rawFeed := `{
+		"version": "https://jsonfeed.org/version/1",
+		"title": "Example",
+		"items": [{
+			"id": "1",
+			"url": "https://example.com/episode",
+			"title": "Episode",
+			"attachments": [
+				{"url": "https://example.com/episode.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 1234, "duration_in_seconds": 1800.5},
+				{"url": "https://example.com/episode.ogg", "mime_type": "audio/ogg", "duration_in_seconds": 1801}
+			]
+		}]
+	}`
+	feed, err := parseFeed([]byte(rawFeed), "")
+	if err != nil {
+		t.Fatalf("parseFeed() error = %v", err)
+	}
+	if len(feed.Channel.Item) != 1 {
+		t.Fatalf("got %d items, want 1", len(feed.Channel.Item))
+	}
+
+	item := feed.Channel.Item[0]
+	want := []RSSEnclosure{
+		{URL: "https://example.com/episode.mp3", Type: "audio/mpeg", Length: "1234"},
+		{URL: "https://example.com/episode.ogg", Type: "audio/ogg"},
+	}
+	if len(item.Enclosures) != len(want) {
+		t.Fatalf("Enclosures = %+v, want %+v", item.Enclosures, want)
+	}
+	for i := range want {
+		if item.Enclosures[i] != want[i] {
+			t.Errorf("Enclosures[%d] = %+v, want %+v", i, item.Enclosures[i], want[i])
+		}
+	}
+	if item.ITunesDuration != "1800" {
+		t.Errorf("ITunesDuration = %q, want %q", item.ITunesDuration, "1800")
+	}
+}
+
+func TestParseFeedJSONVersion(t *testing.T) {
+	tests := []string{
+		`{"title": "Example", "items": []}`,
+		`{"version": "1.1", "title": "Example", "items": []}`,
+		`{"version": "https://example.com/version/1", "title": "Example", "items": []}`,
+	}
+
+	for _, rawFeed := range tests {
+		t.Run(rawFeed, func(t *testing.T) {
+			_, err := parseFeed([]byte(rawFeed), "application/json")
+			if err == nil {
+				t.Errorf("parseFeed(%q) succeeded, want error", rawFeed)
+			}
+		})
+	}
+}
+
+func TestIsJSONFeed(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        bool
+	}{
+		{"feed content type", `{}`, "application/feed+json", true},
+		{"json content type", `{}`, "application/json; charset=utf-8", true},
+		{"plain text json", "\n  {\"version\": \"https://jsonfeed.org/version/1.1\"}", "text/plain", true},
+		{"no content type", `{"version": "https://jsonfeed.org/version/1.1"}`, "", true},
+		{"rss", `<?xml version="1.0"?><rss version="2.0"></rss>`, "application/rss+xml", false},
+		{"xml served as text", `<rss version="2.0"></rss>`, "text/plain", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isJSONFeed([]byte(tt.body), tt.contentType)
+			if got != tt.want {
+				t.Errorf("isJSONFeed(%q, %q) = %t, want %t", tt.body, tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
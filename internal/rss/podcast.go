@@ -0,0 +1,12 @@
+package rss
+
+// RSSEnclosure is a media file attached to an item, such as a podcast episode
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
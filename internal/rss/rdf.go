@@ -0,0 +1,48 @@
+package rss
+
+const rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// RDFFeed is an RSS 1.0 feed. Unlike RSS 2.0, items are siblings of the channel element rather
+// than children of it.
+type RDFFeed struct {
+	Channel struct {
+		Title           string `xml:"title"`
+		Link            string `xml:"link"`
+		Description     string `xml:"description"`
+		UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+	} `xml:"channel"`
+	Item []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+// toRSS converts an RSS 1.0 feed, using the `rdf:about` URI of each item as its GUID
+func (rdf *RDFFeed) toRSS() *RSSFeed {
+	var rssFeed RSSFeed
+	rssFeed.Channel.Title = rdf.Channel.Title
+	rssFeed.Channel.Link = rdf.Channel.Link
+	rssFeed.Channel.Description = rdf.Channel.Description
+	rssFeed.Channel.UpdatePeriod = rdf.Channel.UpdatePeriod
+	rssFeed.Channel.UpdateFrequency = rdf.Channel.UpdateFrequency
+
+	for _, item := range rdf.Item {
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, RSSItem{
+			GUID:        item.About,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     item.Date,
+			Content:     item.Content,
+		})
+	}
+
+	return &rssFeed
+}
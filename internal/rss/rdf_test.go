@@ -0,0 +1,153 @@
+package rss
+
+import (
+	"testing"
+)
+
+func TestParseFeedRDF(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want RSSItem
+	}{
+		{
+			name: "about and date",
+			feed: `<item rdf:about="https://example.com/post">
+				<title>Post</title>
+				<link>https://example.com/post</link>
+				<description>Short summary</description>
+				<dc:date>2024-01-02T03:04:05+00:00</dc:date>
+			</item>`,
+			want: RSSItem{
+				GUID:        "https://example.com/post",
+				Title:       "Post",
+				Link:        "https://example.com/post",
+				Description: "Short summary",
+				PubDate:     "2024-01-02T03:04:05+00:00",
+			},
+		},
+		{
+			name: "about differs from link",
+			feed: `<item rdf:about="https://example.com/?p=2">
+				<title>Post</title>
+				<link>https://example.com/post?utm_source=rss</link>
+				<dc:date>2024-01-02T03:04:05Z</dc:date>
+			</item>`,
+			want: RSSItem{
+				GUID:    "https://example.com/?p=2",
+				Title:   "Post",
+				Link:    "https://example.com/post?utm_source=rss",
+				PubDate: "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "encoded content",
+			feed: `<item rdf:about="https://example.com/post">
+				<title>Post</title>
+				<link>https://example.com/post</link>
+				<description>Short summary</description>
+				<content:encoded><![CDATA[<p>Full content</p>]]></content:encoded>
+			</item>`,
+			want: RSSItem{
+				GUID:        "https://example.com/post",
+				Title:       "Post",
+				Link:        "https://example.com/post",
+				Description: "Short summary",
+				Content:     "<p>Full content</p>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawFeed := `<?xml version="1.0" encoding="utf-8"?>
+				<rdf:RDF
+					xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+					xmlns="http://purl.org/rss/1.0/"
+					xmlns:dc="http://purl.org/dc/elements/1.1/"
+					xmlns:sy="http://purl.org/rss/1.0/modules/syndication/"
+					xmlns:content="http://purl.org/rss/1.0/modules/content/">
+					<channel rdf:about="https://example.com/">
+						<title>Example</title>
+						<link>https://example.com/</link>
+						<description>An example feed</description>
+						<sy:updatePeriod>hourly</sy:updatePeriod>
+						<sy:updateFrequency>2</sy:updateFrequency>
+						<items>
+							<rdf:Seq>
+								<rdf:li rdf:resource="https://example.com/post"/>
+							</rdf:Seq>
+						</items>
+					</channel>
+					` + tt.feed + `
+				</rdf:RDF>`
+			feed, err := parseFeed([]byte(rawFeed), "application/rdf+xml")
+			if err != nil {
+				t.Fatalf("parseFeed() error = %v", err)
+			}
+
+			if feed.Channel.Title != "Example" {
+				t.Errorf("Channel.Title = %q, want %q", feed.Channel.Title, "Example")
+			}
+			if feed.Channel.Link != "https://example.com/" {
+				t.Errorf("Channel.Link = %q, want %q", feed.Channel.Link, "https://example.com/")
+			}
+			if feed.Channel.Description != "An example feed" {
+				t.Errorf("Channel.Description = %q, want %q", feed.Channel.Description, "An example feed")
+			}
+			if interval := feed.UpdateInterval(); interval.Minutes() != 30 {
+				t.Errorf("UpdateInterval() = %s, want 30m0s", interval)
+			}
+			if len(feed.Channel.Item) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Channel.Item))
+			}
+
+			got := feed.Channel.Item[0]
+			if got.GUID != tt.want.GUID {
+				t.Errorf("GUID = %q, want %q", got.GUID, tt.want.GUID)
+			}
+			if got.Title != tt.want.Title {
+				t.Errorf("Title = %q, want %q", got.Title, tt.want.Title)
+			}
+			if got.Link != tt.want.Link {
+				t.Errorf("Link = %q, want %q", got.Link, tt.want.Link)
+			}
+			if got.Description != tt.want.Description {
+				t.Errorf("Description = %q, want %q", got.Description, tt.want.Description)
+			}
+			if got.Content != tt.want.Content {
+				t.Errorf("Content = %q, want %q", got.Content, tt.want.Content)
+			}
+			if got.PubDate != tt.want.PubDate {
+				t.Errorf("PubDate = %q, want %q", got.PubDate, tt.want.PubDate)
+			}
+		})
+	}
+}
+
+func TestParseFeedRDFItemOrder(t *testing.T) {
+	// Items are siblings of the channel and may come before or after it
+	rawFeed := `<?xml version="1.0"?>
+		<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+			<item rdf:about="https://example.com/1"><title>One</title></item>
+			<channel rdf:about="https://example.com/">
+				<title>Example</title>
+			</channel>
+			<item rdf:about="https://example.com/2"><title>Two</title></item>
+			<item rdf:about="https://example.com/3"><title>Three</title></item>
+		</rdf:RDF>`
+	feed, err := parseFeed([]byte(rawFeed), "")
+	if err != nil {
+		t.Fatalf("parseFeed() error = %v", err)
+	}
+
+	want := []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"}
+	if len(feed.Channel.Item) != len(want) {
+		t.Fatalf("got %d items, want %d", len(feed.Channel.Item), len(want))
+	}
+	for i, guid := range want {
+		if feed.Channel.Item[i].GUID != guid {
+			t.Errorf("Item[%d].GUID = %q, want %q", i, feed.Channel.Item[i].GUID, guid)
+		}
+	}
+}
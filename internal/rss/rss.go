@@ -1,66 +1,215 @@
 package rss
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title           string    `xml:"title"`
+		Link            string    `xml:"link"`
+		Description     string    `xml:"description"`
+		TTL             string    `xml:"ttl"`
+		UpdatePeriod    string    `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string    `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+		Item            []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
+	GUID        string `xml:"guid"`
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+
+	// Podcast metadata
+	Enclosures     []RSSEnclosure `xml:"enclosure"`
+	ITunesDuration string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesEpisode  string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	ITunesImage    ITunesImage    `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+}
+
+var syndicationPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   time.Hour * 24,
+	"weekly":  time.Hour * 24 * 7,
+	"monthly": time.Hour * 24 * 30,
+	"yearly":  time.Hour * 24 * 365,
+}
+
+// UpdateInterval returns how long the publisher has asked clients to wait between fetches of the
+// feed, based on the RSS `<ttl>` element or the `sy:updatePeriod` and `sy:updateFrequency`
+// elements. Returns 0 if the feed doesn't provide a hint.
+func (rss *RSSFeed) UpdateInterval() time.Duration {
+	ttl, err := strconv.Atoi(strings.TrimSpace(rss.Channel.TTL))
+	if err == nil && ttl > 0 {
+		return time.Duration(ttl) * time.Minute
+	}
+
+	period, ok := syndicationPeriods[strings.TrimSpace(rss.Channel.UpdatePeriod)]
+	if !ok {
+		return 0
+	}
+	frequency, err := strconv.Atoi(strings.TrimSpace(rss.Channel.UpdateFrequency))
+	if err != nil || frequency < 1 {
+		// `sy:updateFrequency` defaults to 1 when it's missing
+		frequency = 1
+	}
+	return period / time.Duration(frequency)
 }
 
+// cleanRSS unescapes the titles of the feed and its items, which are meant to be plain text. The
+// descriptions and content are left as HTML so that entities such as `&lt;` aren't turned into
+// markup before they're rendered.
 func cleanRSS(rss *RSSFeed) {
 	rss.Channel.Title = html.UnescapeString(rss.Channel.Title)
 	rss.Channel.Description = html.UnescapeString(rss.Channel.Description)
 
 	for i := range rss.Channel.Item {
+		rss.Channel.Item[i].GUID = strings.TrimSpace(rss.Channel.Item[i].GUID)
 		rss.Channel.Item[i].Title = html.UnescapeString(rss.Channel.Item[i].Title)
-		rss.Channel.Item[i].Description = html.UnescapeString(rss.Channel.Item[i].Description)
 	}
 }
 
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// fetchTimeout limits how long a single request, including reading its body, may take. A server
+// that never responds would otherwise hold up `agg` forever. It's kept well below the shortest
+// fetch interval so that a feed is never claimed again while it's still being fetched.
+const fetchTimeout = time.Second * 30
+
+var httpClient = &http.Client{Timeout: fetchTimeout}
+
+// CacheHeaders holds the validators returned by a server so that later requests for the same
+// feed can be made conditional
+type CacheHeaders struct {
+	ETag         string
+	LastModified string
+}
+
+// ErrNotModified is returned by FetchFeed when the server reports that the feed hasn't changed
+// since the last fetch
+var ErrNotModified = errors.New("Feed has not been modified")
+
+// FetchFeed downloads and parses the feed at the given URL. Any non-empty cache headers are sent
+// along with the request so that the server can respond with `304 Not Modified` if the feed
+// hasn't changed, in which case ErrNotModified is returned. The cache headers of the response are
+// returned alongside the feed.
+func FetchFeed(ctx context.Context, feedURL string, cache CacheHeaders) (*RSSFeed, CacheHeaders, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("Error creating request: %w", err)
+		return nil, CacheHeaders{}, fmt.Errorf("Error creating request: %w", err)
 	}
 
 	req.Header.Add("User-Agent", "gator")
+	if cache.ETag != "" {
+		req.Header.Add("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		req.Header.Add("If-Modified-Since", cache.LastModified)
+	}
 
-	client := http.Client{}
-	res, err := client.Do(req)
+	res, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("Error sending request: %w", err)
+		return nil, CacheHeaders{}, fmt.Errorf("Error sending request: %w", err)
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotModified {
+		return nil, cache, ErrNotModified
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, CacheHeaders{}, fmt.Errorf("Unexpected response status: %s", res.Status)
+	}
+
+	resCache := CacheHeaders{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+
 	rssXml, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("Error reading RSS Feed: %w", err)
+		return nil, CacheHeaders{}, fmt.Errorf("Error reading RSS Feed: %w", err)
+	}
+
+	rssFeed, err := parseFeed(rssXml, res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, CacheHeaders{}, err
 	}
+	cleanRSS(rssFeed)
 
-	var rssFeed RSSFeed
-	err = xml.Unmarshal(rssXml, &rssFeed)
+	return rssFeed, resCache, nil
+}
+
+// parseFeed decodes the raw feed based on its content type and root element. Atom, RSS 1.0
+// and JSON feeds are mapped onto the RSS feed model so that the rest of gator can treat every
+// format the same way.
+func parseFeed(rawFeed []byte, contentType string) (*RSSFeed, error) {
+	if isJSONFeed(rawFeed, contentType) {
+		var jsonFeed JSONFeed
+		err := json.Unmarshal(rawFeed, &jsonFeed)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling the raw JSON feed: %w", err)
+		}
+		if !strings.HasPrefix(jsonFeed.Version, jsonFeedVersionPrefix) {
+			return nil, fmt.Errorf("Unrecognized JSON feed version: %q", jsonFeed.Version)
+		}
+		return jsonFeed.toRSS(), nil
+	}
+
+	root, err := rootElement(rawFeed)
 	if err != nil {
-		return nil, fmt.Errorf("Error unmarshaling the raw RSS XML: %w", err)
+		return nil, fmt.Errorf("Error reading the root element of the feed: %w", err)
 	}
-	cleanRSS(&rssFeed)
 
-	return &rssFeed, nil
+	switch {
+	case root.Local == "feed" && root.Space == atomNamespace:
+		var atomFeed AtomFeed
+		err = xml.Unmarshal(rawFeed, &atomFeed)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling the raw Atom XML: %w", err)
+		}
+		return atomFeed.toRSS(), nil
+	case root.Local == "RDF" && root.Space == rdfNamespace:
+		var rdfFeed RDFFeed
+		err = xml.Unmarshal(rawFeed, &rdfFeed)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling the raw RDF XML: %w", err)
+		}
+		return rdfFeed.toRSS(), nil
+	case root.Local == "rss":
+		var rssFeed RSSFeed
+		err = xml.Unmarshal(rawFeed, &rssFeed)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling the raw RSS XML: %w", err)
+		}
+		return &rssFeed, nil
+	default:
+		return nil, fmt.Errorf("Unrecognized feed format with root element <%s>", root.Local)
+	}
+}
+
+// rootElement returns the name of the first element in an XML document
+func rootElement(rawXml []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(rawXml))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
 }
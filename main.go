@@ -27,6 +27,7 @@ func main() {
 	st := State{
 		DB:     dbQueries,
 		Config: cfg,
+		Conn:   db,
 	}
 
 	cmds := NewCommands()
@@ -34,22 +35,11 @@ func main() {
 	// Maybe combine the logic for running commands into a single function
 	userArgs := os.Args
 	if len(userArgs) < 2 {
-		fmt.Println("Not enough arguments")
+		cmds.PrintUsage(os.Stderr)
 		os.Exit(1)
 	}
 
-	cmdName := userArgs[1]
-	cmdArgs := []string{}
-	if len(userArgs) >= 3 {
-		cmdArgs = userArgs[2:]
-	}
-
-	cmd := Command{
-		Name: cmdName,
-		Args: cmdArgs,
-	}
-
-	err = cmds.Run(&st, cmd)
+	err = cmds.Run(&st, userArgs[1:])
 	if err != nil {
 		fmt.Printf("%s\n", err.Error())
 		os.Exit(1)
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/TheSeaGiraffe/gator/internal/database"
+)
+
+// outputFlag is the flag shared by all of the commands that list records. Text output is meant for
+// people and may change, the other formats are stable and meant for scripts.
+var outputFlag = FlagSpec{
+	Name:     "output",
+	Default:  "text",
+	Usage:    "Output format, one of 'text', 'json', 'csv' or 'tsv'",
+	Complete: completeOutputFormats,
+}
+
+var outputFormats = []string{"text", "json", "csv", "tsv"}
+
+// Field is a single named value of a record. Values should be strings, numbers, booleans,
+// `time.Time`s or one of the `sql.Null*` types, which are rendered as null/empty when invalid.
+type Field struct {
+	Name  string
+	Value any
+}
+
+// Record is a single item in the output of a listing command. The order of the fields is kept in
+// every output format.
+type Record []Field
+
+// outputFormat returns the output format requested for a command
+func outputFormat(cmd Command) (string, error) {
+	format := cmd.String("output")
+	for _, f := range outputFormats {
+		if format == f {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("Unsupported output format '%s'. Supported formats: %s", format, strings.Join(outputFormats, ", "))
+}
+
+// renderRecords writes the records in one of the machine-readable output formats. Text output is
+// left to each command since it's formatted differently for each kind of record.
+func renderRecords(w io.Writer, format string, fields []string, records []Record) error {
+	switch format {
+	case "json":
+		return renderJSON(w, records)
+	case "csv":
+		return renderCSV(w, fields, records)
+	case "tsv":
+		return renderTSV(w, fields, records)
+	default:
+		return fmt.Errorf("Unsupported output format '%s'", format)
+	}
+}
+
+// renderJSON writes the records as a JSON array of objects
+func renderJSON(w io.Writer, records []Record) error {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, record := range records {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j, field := range record {
+			if j > 0 {
+				buf.WriteString(",")
+			}
+			name, err := json.Marshal(field.Name)
+			if err != nil {
+				return fmt.Errorf("Error encoding field name: %w", err)
+			}
+			value, err := json.Marshal(jsonValue(field.Value))
+			if err != nil {
+				return fmt.Errorf("Error encoding field %q: %w", field.Name, err)
+			}
+			fmt.Fprintf(&buf, "\n    %s: %s", name, value)
+		}
+		buf.WriteString("\n  }")
+	}
+	if len(records) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// renderCSV writes the records as CSV with a header row containing the field names
+func renderCSV(w io.Writer, fields []string, records []Record) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(fields)
+	if err != nil {
+		return fmt.Errorf("Error writing header: %w", err)
+	}
+
+	row := make([]string, len(fields))
+	for _, record := range records {
+		for i, field := range record {
+			row[i] = textValue(field.Value)
+		}
+		err = writer.Write(row)
+		if err != nil {
+			return fmt.Errorf("Error writing record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// renderTSV writes the records as TSV with a header row containing the field names. TSV has no
+// way of escaping tabs or newlines so any runs of whitespace in the values are collapsed into a
+// single space.
+func renderTSV(w io.Writer, fields []string, records []Record) error {
+	var buf bytes.Buffer
+	buf.WriteString(strings.Join(fields, "\t") + "\n")
+
+	row := make([]string, len(fields))
+	for _, record := range records {
+		for i, field := range record {
+			row[i] = strings.Join(strings.Fields(textValue(field.Value)), " ")
+		}
+		buf.WriteString(strings.Join(row, "\t") + "\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// jsonValue converts the nullable SQL types to values that encode as JSON nulls when invalid
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case sql.NullString:
+		if v.Valid {
+			return v.String
+		}
+		return nil
+	case sql.NullTime:
+		if v.Valid {
+			return v.Time.UTC()
+		}
+		return nil
+	case sql.NullInt32:
+		if v.Valid {
+			return v.Int32
+		}
+		return nil
+	case sql.NullInt64:
+		if v.Valid {
+			return v.Int64
+		}
+		return nil
+	case time.Time:
+		return v.UTC()
+	default:
+		return v
+	}
+}
+
+// textValue formats a field value for CSV and TSV output. Times use the same format as in JSON.
+func textValue(value any) string {
+	switch v := jsonValue(value).(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fieldNames returns the names of the fields of a record
+func fieldNames(record Record) []string {
+	names := make([]string, 0, len(record))
+	for _, field := range record {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+func userRecord(user database.User, current bool) Record {
+	return Record{
+		{"id", user.ID},
+		{"name", user.Name},
+		{"current", current},
+		{"created_at", user.CreatedAt},
+		{"updated_at", user.UpdatedAt},
+	}
+}
+
+func feedRecord(feed database.Feed, owner string) Record {
+	return Record{
+		{"id", feed.ID},
+		{"name", feed.Name},
+		{"url", feed.Url},
+		{"owner", owner},
+		{"created_at", feed.CreatedAt},
+		{"updated_at", feed.UpdatedAt},
+		{"last_fetched_at", feed.LastFetchedAt},
+		{"next_fetch_at", feed.NextFetchAt},
+		{"last_success_at", feed.LastSuccessAt},
+		{"consecutive_failures", feed.ConsecutiveFailures},
+		{"last_error", feed.LastError},
+		{"disabled", feed.Disabled},
+	}
+}
+
+func feedFollowRecord(feedFollow database.GetFeedFollowsForUserRow) Record {
+	return Record{
+		{"id", feedFollow.ID},
+		{"feed_id", feedFollow.FeedID},
+		{"feed_name", feedFollow.FeedName},
+		{"feed_url", feedFollow.FeedUrl},
+		{"folder", feedFollow.Folder},
+		{"followed_at", feedFollow.CreatedAt},
+	}
+}
+
+func postRecord(post database.GetPostsForUserRow) Record {
+	return Record{
+		{"id", post.ID},
+		{"feed_id", post.FeedID},
+		{"title", post.Title},
+		{"url", post.Url},
+		{"guid", post.Guid},
+		{"published_at", post.PublishedAt},
+		{"created_at", post.CreatedAt},
+		{"updated_at", post.UpdatedAt},
+		{"description", post.Description},
+		{"duration", post.Duration},
+		{"episode", post.Episode},
+		{"image_url", post.ImageUrl},
+	}
+}
+
+func browseRecord(post database.GetPostsForUserRow) Record {
+	return append(postRecord(post), Field{"cursor", encodePostCursor(post)})
+}
+
+func searchResultRecord(result database.SearchPostsForUserRow) Record {
+	return Record{
+		{"id", result.ID},
+		{"title", result.Title},
+		{"url", result.Url},
+		{"feed_name", result.FeedName},
+		{"published_at", result.PublishedAt},
+		{"rank", result.Rank},
+		{"snippet", result.Snippet},
+	}
+}
+
+func completeOutputFormats(s *State) ([]string, error) {
+	return outputFormats, nil
+}
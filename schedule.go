@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/TheSeaGiraffe/gator/internal/database"
+)
+
+var (
+	// Feeds are claimed until their next fetch is due, so this has to stay well above the
+	// timeout of a single fetch
+	minFetchInterval = time.Minute * 5
+	maxFetchInterval = time.Hour * 24
+	maxRetryInterval = time.Hour * 24
+
+	// Feeds are disabled after failing this many times in a row
+	maxConsecutiveFailures = 10
+)
+
+// nextFetchInterval adapts the fetch interval of a feed to how often it posts. Feeds that had new
+// posts are fetched twice as often while feeds that didn't are backed off by half of their current
+// interval. The publisher's update hint, if any, is used as a lower bound.
+func nextFetchInterval(current time.Duration, newPosts int, hint time.Duration) time.Duration {
+	var next time.Duration
+	if newPosts > 0 {
+		next = current / 2
+	} else {
+		next = current + current/2
+	}
+
+	next = min(max(next, minFetchInterval), maxFetchInterval)
+
+	// Always honour the publisher's hint, even if it's longer than our own maximum
+	return max(next, hint)
+}
+
+// scheduleNextFetch works out when a feed should be fetched next and saves it to the database
+func scheduleNextFetch(s *State, feed database.Feed, newPosts int, hint time.Duration) error {
+	current := time.Duration(feed.FetchInterval) * time.Second
+	next := nextFetchInterval(current, newPosts, hint)
+
+	scheduleParams := database.UpdateFeedScheduleParams{
+		ID:            feed.ID,
+		FetchInterval: int32(next.Seconds()),
+		NextFetchAt: sql.NullTime{
+			Time:  time.Now().Add(next),
+			Valid: true,
+		},
+	}
+	err := s.DB.UpdateFeedSchedule(context.Background(), scheduleParams)
+	if err != nil {
+		return fmt.Errorf("Error scheduling next fetch: %w", err)
+	}
+
+	return nil
+}
+
+// retryInterval returns how long to wait before fetching a failing feed again. The wait doubles
+// with every consecutive failure.
+func retryInterval(fetchInterval time.Duration, failures int) time.Duration {
+	retry := fetchInterval
+	for i := 1; i < failures && retry < maxRetryInterval; i++ {
+		retry *= 2
+	}
+	return min(retry, maxRetryInterval)
+}
+
+// scheduleRetry records a failed fetch and backs off the next fetch of the feed. Feeds that have
+// failed too many times in a row are disabled.
+func scheduleRetry(s *State, feed database.Feed, fetchErr error) error {
+	failures := int(feed.ConsecutiveFailures) + 1
+	retry := retryInterval(time.Duration(feed.FetchInterval)*time.Second, failures)
+
+	failureParams := database.RecordFeedFailureParams{
+		ID: feed.ID,
+		LastError: sql.NullString{
+			String: fetchErr.Error(),
+			Valid:  true,
+		},
+		ConsecutiveFailures: int32(failures),
+		NextFetchAt: sql.NullTime{
+			Time:  time.Now().Add(retry),
+			Valid: true,
+		},
+		Disabled: failures >= maxConsecutiveFailures,
+	}
+	err := s.DB.RecordFeedFailure(context.Background(), failureParams)
+	if err != nil {
+		return fmt.Errorf("Error recording failed fetch: %w", err)
+	}
+
+	if failureParams.Disabled {
+		fmt.Printf("Feed %q has been disabled after %d consecutive failures\n", feed.Name, failures)
+	}
+
+	return nil
+}
@@ -1,7 +1,7 @@
 -- name: CreateFeedFollow :one
 WITH new_record AS (
-    INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id)
-    VALUES($1, $2, $3, $4)
+    INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id, folder)
+    VALUES($1, $2, $3, $4, $5)
     RETURNING *
 )
 SELECT
@@ -16,15 +16,26 @@ INNER JOIN feeds ON new_record.feed_id = feeds.id;
 
 -- name: GetFeedFollowsForUser :many
 SELECT
+    feed_follows.id,
+    feed_follows.created_at,
+    feed_follows.feed_id,
     users.name AS user_name,
-    feeds.name AS feed_name
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    feed_follows.folder
 FROM feed_follows
 INNER JOIN users ON feed_follows.user_id = users.id
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
-WHERE feed_follows.user_id = $1;
+WHERE feed_follows.user_id = $1
+ORDER BY feed_follows.folder NULLS FIRST, feeds.name;
 
 -- name: DeleteFeed :exec
 DELETE FROM feed_follows
 WHERE
     user_id = $1 AND
     feed_id = $2;
+
+-- name: FollowFeedIfNotFollowing :execrows
+INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id, folder)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, feed_id) DO NOTHING;
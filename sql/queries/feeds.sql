@@ -16,14 +16,114 @@ SELECT * FROM feeds;
 SELECT * FROM feeds
 WHERE url = $1;
 
--- name: MarkFeedFetched :exec
+-- name: GetFollowedFeedByURL :one
+SELECT feeds.* FROM feeds
+INNER JOIN feed_follows ON
+    feeds.id = feed_follows.feed_id
+WHERE
+    feeds.url = $1 AND
+    feed_follows.user_id = $2;
+
+-- name: ClaimNextFeedToFetch :one
+UPDATE feeds
+SET
+    updated_at = now(),
+    last_fetched_at = now(),
+    next_fetch_at = now() + make_interval(secs => fetch_interval)
+WHERE id = (
+    SELECT id FROM feeds
+    WHERE
+        (next_fetch_at IS NULL OR next_fetch_at <= now()) AND
+        NOT disabled
+    ORDER BY next_fetch_at ASC NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateFeedCacheHeaders :exec
 UPDATE feeds
 SET
     updated_at = now(),
-    last_fetched_at = now()
+    etag = $2,
+    last_modified = $3
 WHERE id = $1;
 
--- name: GetNextFeedToFetch :one
+-- name: UpdateFeedSchedule :exec
+UPDATE feeds
+SET
+    updated_at = now(),
+    fetch_interval = $2,
+    next_fetch_at = $3
+WHERE id = $1;
+
+-- name: GetFeedsWithErrors :many
 SELECT * FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
+WHERE consecutive_failures > 0 OR disabled
+ORDER BY consecutive_failures DESC;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET
+    updated_at = now(),
+    last_error = NULL,
+    consecutive_failures = 0,
+    last_success_at = now()
+WHERE id = $1;
+
+-- name: RecordFeedFailure :exec
+UPDATE feeds
+SET
+    updated_at = now(),
+    last_error = $2,
+    consecutive_failures = $3,
+    next_fetch_at = $4,
+    disabled = $5
+WHERE id = $1;
+
+-- name: GetFeedsByName :many
+SELECT * FROM feeds
+WHERE name = $1;
+
+-- name: UpdateFeed :one
+UPDATE feeds
+SET
+    updated_at = now(),
+    name = $2,
+    url = $3,
+    etag = CASE WHEN url = $3 THEN etag END,
+    last_modified = CASE WHEN url = $3 THEN last_modified END,
+    next_fetch_at = CASE WHEN url = $3 THEN next_fetch_at END,
+    last_error = CASE WHEN url = $3 THEN last_error END,
+    consecutive_failures = CASE WHEN url = $3 THEN consecutive_failures ELSE 0 END,
+    disabled = CASE WHEN url = $3 THEN disabled ELSE false END
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFeedByID :exec
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: CountFeedPostsAndFollows :one
+SELECT
+    (SELECT count(*) FROM posts WHERE posts.feed_id = $1) AS post_count,
+    (
+        SELECT count(*) FROM post_stars
+        INNER JOIN posts ON post_stars.post_id = posts.id
+        WHERE posts.feed_id = $1
+    ) AS star_count,
+    (SELECT count(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS follow_count,
+    (
+        SELECT count(*) FROM feed_follows
+        WHERE feed_follows.feed_id = $1 AND feed_follows.user_id <> $2
+    ) AS other_follower_count;
+
+-- name: EnableFeed :exec
+UPDATE feeds
+SET
+    updated_at = now(),
+    last_error = NULL,
+    consecutive_failures = 0,
+    next_fetch_at = NULL,
+    disabled = false
+WHERE id = $1;
@@ -0,0 +1,24 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, $2
+FROM posts
+INNER JOIN feed_follows ON
+    posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkAllFeedPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, $2
+FROM posts
+INNER JOIN feed_follows ON
+    posts.feed_id = feed_follows.feed_id
+WHERE
+    feed_follows.user_id = $1 AND
+    posts.feed_id = $3
+ON CONFLICT (user_id, post_id) DO NOTHING;
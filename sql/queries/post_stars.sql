@@ -0,0 +1,32 @@
+-- name: StarPost :exec
+INSERT INTO post_stars (user_id, post_id, starred_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnstarPost :execrows
+DELETE FROM post_stars
+WHERE
+    user_id = $1 AND
+    post_id = $2;
+
+-- name: GetStarredPostsForUser :many
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.guid,
+    posts.content,
+    posts.duration,
+    posts.episode,
+    posts.image_url
+FROM posts
+INNER JOIN post_stars ON
+    posts.id = post_stars.post_id
+WHERE post_stars.user_id = $1
+ORDER BY post_stars.starred_at DESC
+LIMIT $2;
@@ -1,7 +1,25 @@
 -- name: CreatePost :one
-INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING *;
+INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id, guid, content, duration, episode, image_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET
+    updated_at = EXCLUDED.updated_at,
+    title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    content = EXCLUDED.content,
+    duration = EXCLUDED.duration,
+    episode = EXCLUDED.episode,
+    image_url = EXCLUDED.image_url
+WHERE
+    posts.title IS DISTINCT FROM EXCLUDED.title OR
+    posts.url IS DISTINCT FROM EXCLUDED.url OR
+    posts.description IS DISTINCT FROM EXCLUDED.description OR
+    posts.content IS DISTINCT FROM EXCLUDED.content OR
+    posts.duration IS DISTINCT FROM EXCLUDED.duration OR
+    posts.episode IS DISTINCT FROM EXCLUDED.episode OR
+    posts.image_url IS DISTINCT FROM EXCLUDED.image_url
+RETURNING id, created_at, updated_at;
 
 -- name: GetPostsForUser :many
 SELECT
@@ -12,10 +30,124 @@ SELECT
     posts.url,
     posts.description,
     posts.published_at,
-    posts.feed_id
+    posts.feed_id,
+    posts.guid,
+    posts.content,
+    posts.duration,
+    posts.episode,
+    posts.image_url
 FROM posts
 INNER JOIN feed_follows ON
     posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2;
+LEFT JOIN post_reads ON
+    posts.id = post_reads.post_id AND
+    post_reads.user_id = feed_follows.user_id
+WHERE
+    feed_follows.user_id = sqlc.arg(user_id) AND
+    (NOT sqlc.arg(unread_only)::boolean OR post_reads.post_id IS NULL) AND
+    (sqlc.narg(feed_id)::int IS NULL OR posts.feed_id = sqlc.narg(feed_id)) AND
+    (sqlc.narg(since)::timestamptz IS NULL OR posts.published_at >= sqlc.narg(since)) AND
+    (sqlc.narg(until)::timestamptz IS NULL OR posts.published_at < sqlc.narg(until)) AND
+    (
+        sqlc.narg(cursor_published_at)::timestamptz IS NULL OR
+        (
+            sqlc.arg(ascending)::boolean AND
+            (posts.published_at, posts.id) > (sqlc.narg(cursor_published_at), sqlc.narg(cursor_id)::int)
+        ) OR
+        (
+            NOT sqlc.arg(ascending)::boolean AND
+            (posts.published_at, posts.id) < (sqlc.narg(cursor_published_at), sqlc.narg(cursor_id)::int)
+        )
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(ascending)::boolean THEN posts.published_at END ASC,
+    CASE WHEN sqlc.arg(ascending)::boolean THEN posts.id END ASC,
+    posts.published_at DESC,
+    posts.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetPostByID :one
+SELECT
+    id,
+    created_at,
+    updated_at,
+    title,
+    url,
+    description,
+    published_at,
+    feed_id,
+    guid,
+    content,
+    duration,
+    episode,
+    image_url
+FROM posts
+WHERE id = $1;
+
+-- name: GetPostForUser :one
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.guid,
+    posts.content,
+    posts.duration,
+    posts.episode,
+    posts.image_url
+FROM posts
+INNER JOIN feed_follows ON
+    posts.feed_id = feed_follows.feed_id
+WHERE
+    posts.id = $1 AND
+    feed_follows.user_id = $2;
+
+-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(posts.search_vector, query) AS rank,
+    ts_headline(
+        'english',
+        concat_ws(' ', posts.description, posts.content),
+        query,
+        'StartSel=**, StopSel=**, MaxFragments=2, MaxWords=30, MinWords=10'
+    ) AS snippet
+FROM
+    posts
+    INNER JOIN feed_follows ON
+        posts.feed_id = feed_follows.feed_id
+    INNER JOIN feeds ON
+        posts.feed_id = feeds.id,
+    websearch_to_tsquery('english', sqlc.arg(query)) AS query
+WHERE
+    feed_follows.user_id = sqlc.arg(user_id) AND
+    posts.search_vector @@ query
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: AdoptPostByURL :exec
+UPDATE posts
+SET guid = sqlc.arg(guid)
+WHERE
+    feed_id = sqlc.arg(feed_id) AND
+    guid = sqlc.arg(url) AND
+    url = sqlc.arg(url) AND
+    NOT EXISTS (
+        SELECT 1 FROM posts AS existing
+        WHERE existing.feed_id = sqlc.arg(feed_id) AND existing.guid = sqlc.arg(guid)
+    );
+
+-- name: GetPostIDByGUID :one
+SELECT id FROM posts
+WHERE
+    feed_id = $1 AND
+    guid = $2;
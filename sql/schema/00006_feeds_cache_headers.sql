@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feeds
+ADD COLUMN etag text,
+ADD COLUMN last_modified text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feeds
+DROP COLUMN etag,
+DROP COLUMN last_modified;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- `fetch_interval` is the number of seconds to wait between fetches of a feed
+ALTER TABLE feeds
+ADD COLUMN fetch_interval int NOT NULL DEFAULT 1800,
+ADD COLUMN next_fetch_at timestamp(0) with time zone;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feeds
+DROP COLUMN fetch_interval,
+DROP COLUMN next_fetch_at;
+-- +goose StatementEnd
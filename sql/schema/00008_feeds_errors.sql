@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feeds
+ADD COLUMN last_error text,
+ADD COLUMN consecutive_failures int NOT NULL DEFAULT 0,
+ADD COLUMN last_success_at timestamp(0) with time zone,
+ADD COLUMN disabled boolean NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feeds
+DROP COLUMN last_error,
+DROP COLUMN consecutive_failures,
+DROP COLUMN last_success_at,
+DROP COLUMN disabled;
+-- +goose StatementEnd
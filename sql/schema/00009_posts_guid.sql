@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts
+ADD COLUMN guid text;
+
+-- Existing posts were identified by their URL. They're given their real GUIDs the next time
+-- their feed is fetched.
+UPDATE posts
+SET guid = url;
+
+ALTER TABLE posts
+ALTER COLUMN guid SET NOT NULL,
+DROP CONSTRAINT posts_url_key,
+ADD CONSTRAINT posts_feed_id_guid_key UNIQUE (feed_id, guid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Posts from different feeds, or with different GUIDs, may share a URL. Only the oldest of
+-- them can be kept once URLs have to be unique again.
+DELETE FROM posts AS newer
+USING posts AS older
+WHERE
+    newer.url = older.url AND
+    newer.id > older.id;
+
+ALTER TABLE posts
+DROP CONSTRAINT posts_feed_id_guid_key,
+ADD CONSTRAINT posts_url_key UNIQUE (url),
+DROP COLUMN guid;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE post_enclosures (
+    id serial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL,
+    updated_at timestamp(0) with time zone NOT NULL,
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    url text NOT NULL,
+    mime_type text,
+    length bigint,
+    duration text,
+    episode int,
+    image_url text,
+    UNIQUE (post_id, url)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE post_enclosures;
+-- +goose StatementEnd
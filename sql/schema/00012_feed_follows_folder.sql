@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feed_follows
+ADD COLUMN folder text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feed_follows
+DROP COLUMN folder;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE post_reads (
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    read_at timestamp(0) with time zone NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE post_reads;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts
+ADD COLUMN duration text,
+ADD COLUMN episode int,
+ADD COLUMN image_url text;
+
+-- The podcast metadata belongs to the episode rather than to any one of its media files
+UPDATE posts
+SET
+    duration = enclosures.duration,
+    episode = enclosures.episode,
+    image_url = enclosures.image_url
+FROM (
+    SELECT DISTINCT ON (post_id) post_id, duration, episode, image_url
+    FROM post_enclosures
+    ORDER BY post_id, id
+) AS enclosures
+WHERE posts.id = enclosures.post_id;
+
+ALTER TABLE post_enclosures
+DROP COLUMN duration,
+DROP COLUMN episode,
+DROP COLUMN image_url;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE post_enclosures
+ADD COLUMN duration text,
+ADD COLUMN episode int,
+ADD COLUMN image_url text;
+
+UPDATE post_enclosures
+SET
+    duration = posts.duration,
+    episode = posts.episode,
+    image_url = posts.image_url
+FROM posts
+WHERE post_enclosures.post_id = posts.id;
+
+ALTER TABLE posts
+DROP COLUMN duration,
+DROP COLUMN episode,
+DROP COLUMN image_url;
+-- +goose StatementEnd
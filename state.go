@@ -1,10 +1,15 @@
 package main
 
 import (
+	"database/sql"
+
 	"github.com/TheSeaGiraffe/gator/internal/database"
 )
 
 type State struct {
 	DB     *database.Queries
 	Config *Config
+
+	// Conn is the underlying DB connection. It's only needed for starting transactions.
+	Conn *sql.DB
 }
@@ -0,0 +1,567 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TheSeaGiraffe/gator/internal/database"
+	"github.com/TheSeaGiraffe/gator/internal/htmltext"
+)
+
+// tuiPostLimit is the maximum number of posts loaded for the selected feed
+var tuiPostLimit = 200
+
+type tuiPane int
+
+const (
+	paneFeeds tuiPane = iota
+	panePosts
+	paneReader
+)
+
+// tuiFeed is an entry in the feed list. The first entry has no feed ID and shows the posts from
+// every feed that the user follows.
+type tuiFeed struct {
+	ID   sql.NullInt32
+	Name string
+}
+
+// tui holds the state of the terminal interface started by the `tui` subcommand
+type tui struct {
+	s    *State
+	user database.User
+
+	feeds   []tuiFeed
+	posts   []database.GetPostsForUserRow
+	unread  map[int32]bool
+	starred map[int32]bool
+
+	focus      tuiPane
+	unreadOnly bool
+	feedIdx    int
+	feedTop    int
+	postIdx    int
+	postTop    int
+	readerTop  int
+	status     string
+
+	width  int
+	height int
+	out    strings.Builder
+}
+
+// HandlerTui is a handler for the `tui` subcommand. `tui` starts a full-screen terminal interface
+// for browsing the posts from the feeds that the current user follows.
+func HandlerTui(s *State, cmd Command, user database.User) error {
+	t := &tui{
+		s:       s,
+		user:    user,
+		unread:  make(map[int32]bool),
+		starred: make(map[int32]bool),
+	}
+
+	err := t.loadFeeds()
+	if err != nil {
+		return err
+	}
+	err = t.loadPosts()
+	if err != nil {
+		return err
+	}
+
+	restore, err := enableRawMode()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	// Switch to the alternate screen so that the user's scrollback is left alone and hide the
+	// cursor while the interface is running
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	buf := make([]byte, 32)
+	for {
+		err = t.draw()
+		if err != nil {
+			return err
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return fmt.Errorf("Error reading input: %w", err)
+		}
+		if !t.handleKey(parseKey(buf[:n])) {
+			return nil
+		}
+	}
+}
+
+// loadFeeds loads the feeds that the user follows into the feed list
+func (t *tui) loadFeeds() error {
+	feedFollows, err := t.s.DB.GetFeedFollowsForUser(context.Background(), t.user.ID)
+	if err != nil {
+		return fmt.Errorf("Could not retrieve feeds for current user: %w", err)
+	}
+
+	t.feeds = []tuiFeed{{Name: "All feeds"}}
+	for _, feedFollow := range feedFollows {
+		name := feedFollow.FeedName
+		if feedFollow.Folder.Valid {
+			name = feedFollow.Folder.String + "/" + name
+		}
+		t.feeds = append(t.feeds, tuiFeed{
+			ID:   sql.NullInt32{Int32: feedFollow.FeedID, Valid: true},
+			Name: name,
+		})
+	}
+	t.feedIdx = min(t.feedIdx, len(t.feeds)-1)
+
+	return nil
+}
+
+// loadPosts loads the posts of the selected feed along with which of them are unread or starred
+func (t *tui) loadPosts() error {
+	postParams := database.GetPostsForUserParams{
+		UserID:     t.user.ID,
+		UnreadOnly: t.unreadOnly,
+		FeedID:     t.feeds[t.feedIdx].ID,
+		Limit:      int32(tuiPostLimit),
+	}
+	posts, err := t.s.DB.GetPostsForUser(context.Background(), postParams)
+	if err != nil {
+		return fmt.Errorf("Error retrieving posts: %w", err)
+	}
+
+	postParams.UnreadOnly = true
+	unreadPosts, err := t.s.DB.GetPostsForUser(context.Background(), postParams)
+	if err != nil {
+		return fmt.Errorf("Error retrieving posts: %w", err)
+	}
+
+	starredParams := database.GetStarredPostsForUserParams{
+		UserID: t.user.ID,
+		Limit:  math.MaxInt32,
+	}
+	starredPosts, err := t.s.DB.GetStarredPostsForUser(context.Background(), starredParams)
+	if err != nil {
+		return fmt.Errorf("Error retrieving starred posts: %w", err)
+	}
+
+	t.posts = posts
+	clear(t.unread)
+	for _, post := range unreadPosts {
+		t.unread[post.ID] = true
+	}
+	clear(t.starred)
+	for _, post := range starredPosts {
+		t.starred[post.ID] = true
+	}
+	t.postIdx = 0
+	t.postTop = 0
+	t.readerTop = 0
+
+	return nil
+}
+
+// selectedPost returns the post under the cursor in the post list, if there is one
+func (t *tui) selectedPost() (database.GetPostsForUserRow, bool) {
+	if len(t.posts) == 0 {
+		return database.GetPostsForUserRow{}, false
+	}
+	return t.posts[t.postIdx], true
+}
+
+// handleKey updates the state of the interface in response to a key press. Returns false when
+// the user wants to quit.
+func (t *tui) handleKey(key string) bool {
+	t.status = ""
+	switch key {
+	case "q", "ctrl+c":
+		return false
+	case "tab", "right", "l":
+		t.focus = min(t.focus+1, paneReader)
+	case "shift+tab", "left", "h":
+		t.focus = max(t.focus-1, paneFeeds)
+	case "down", "j":
+		t.move(1)
+	case "up", "k":
+		t.move(-1)
+	case "g":
+		t.move(-math.MaxInt32 / 2)
+	case "G":
+		t.move(math.MaxInt32 / 2)
+	case " ", "pgdown":
+		t.readerTop += t.readerHeight() - 1
+	case "pgup":
+		t.readerTop = max(t.readerTop-t.readerHeight()+1, 0)
+	case "enter":
+		switch t.focus {
+		case paneFeeds:
+			t.focus = panePosts
+		case panePosts:
+			t.focus = paneReader
+			t.markRead()
+		}
+	case "m":
+		t.markRead()
+	case "s":
+		t.toggleStar()
+	case "o":
+		t.openPost()
+	case "u":
+		t.unreadOnly = !t.unreadOnly
+		t.reload()
+	case "R":
+		t.reload()
+	}
+	return true
+}
+
+// move moves the cursor of the focused pane, or scrolls the reader, by the given amount
+func (t *tui) move(delta int) {
+	switch t.focus {
+	case paneFeeds:
+		newIdx := clamp(t.feedIdx+delta, 0, len(t.feeds)-1)
+		if newIdx != t.feedIdx {
+			t.feedIdx = newIdx
+			t.reload()
+		}
+	case panePosts:
+		t.postIdx = clamp(t.postIdx+delta, 0, max(len(t.posts)-1, 0))
+		t.readerTop = 0
+	case paneReader:
+		t.readerTop = max(t.readerTop+delta, 0)
+	}
+}
+
+func (t *tui) reload() {
+	err := t.loadPosts()
+	if err != nil {
+		t.status = err.Error()
+	}
+}
+
+func (t *tui) markRead() {
+	post, ok := t.selectedPost()
+	if !ok || !t.unread[post.ID] {
+		return
+	}
+
+	postReadParams := database.MarkPostReadParams{
+		UserID: t.user.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	}
+	err := t.s.DB.MarkPostRead(context.Background(), postReadParams)
+	if err != nil {
+		t.status = fmt.Sprintf("Error marking post as read: %s", err)
+		return
+	}
+	delete(t.unread, post.ID)
+}
+
+func (t *tui) toggleStar() {
+	post, ok := t.selectedPost()
+	if !ok {
+		return
+	}
+
+	if t.starred[post.ID] {
+		unstarParams := database.UnstarPostParams{
+			UserID: t.user.ID,
+			PostID: post.ID,
+		}
+		_, err := t.s.DB.UnstarPost(context.Background(), unstarParams)
+		if err != nil {
+			t.status = fmt.Sprintf("Error unstarring post: %s", err)
+			return
+		}
+		delete(t.starred, post.ID)
+		t.status = fmt.Sprintf("Unstarred '%s'", post.Title)
+		return
+	}
+
+	starParams := database.StarPostParams{
+		UserID:    t.user.ID,
+		PostID:    post.ID,
+		StarredAt: time.Now(),
+	}
+	err := t.s.DB.StarPost(context.Background(), starParams)
+	if err != nil {
+		t.status = fmt.Sprintf("Error starring post: %s", err)
+		return
+	}
+	t.starred[post.ID] = true
+	t.status = fmt.Sprintf("Starred '%s'", post.Title)
+}
+
+// openPost opens the selected post in the browser and marks it as read
+func (t *tui) openPost() {
+	post, ok := t.selectedPost()
+	if !ok {
+		return
+	}
+
+	err := openInBrowser(t.s.Config.Browser(), post.Url)
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+	t.markRead()
+	t.status = fmt.Sprintf("Opened %s", post.Url)
+}
+
+// Layout. The feed list takes up the left of the screen while the post list and the reader share
+// the right, with a status line at the bottom.
+
+func (t *tui) feedsWidth() int {
+	return clamp(t.width/4, 12, 32)
+}
+
+func (t *tui) postsHeight() int {
+	return max((t.height-1)*2/5, 3)
+}
+
+func (t *tui) readerHeight() int {
+	return max(t.height-1-t.postsHeight()-1, 1)
+}
+
+// draw redraws the whole screen
+func (t *tui) draw() error {
+	rows, cols, err := terminalSize()
+	if err != nil {
+		return err
+	}
+	t.height, t.width = rows, cols
+
+	feedsWidth := t.feedsWidth()
+	mainWidth := max(t.width-feedsWidth-1, 1)
+
+	feedLines := t.feedLines(feedsWidth, t.height-1)
+	mainLines := append(t.postLines(mainWidth, t.postsHeight()), t.readerLines(mainWidth, t.readerHeight()+1)...)
+
+	t.out.Reset()
+	t.out.WriteString("\x1b[H")
+	for i := 0; i < t.height-1; i++ {
+		t.out.WriteString(lineAt(feedLines, i, feedsWidth))
+		t.out.WriteString("\x1b[2m│\x1b[0m")
+		t.out.WriteString(lineAt(mainLines, i, mainWidth))
+		t.out.WriteString("\x1b[K\r\n")
+	}
+
+	status := t.status
+	if status == "" {
+		status = "q quit  tab/h/l switch pane  j/k move  enter read  space scroll  m mark read  s star  o open  u unread only  R reload"
+	}
+	t.out.WriteString("\x1b[7m" + fit(status, t.width) + "\x1b[0m")
+
+	_, err = os.Stdout.WriteString(t.out.String())
+	return err
+}
+
+func (t *tui) feedLines(width, height int) []string {
+	lines := []string{header("Feeds", width, t.focus == paneFeeds)}
+	t.feedTop = scrollTo(t.feedIdx, t.feedTop, height-1)
+	for i := t.feedTop; i < len(t.feeds) && len(lines) < height; i++ {
+		lines = append(lines, selectable(" "+t.feeds[i].Name, width, i == t.feedIdx, t.focus == paneFeeds))
+	}
+	return lines
+}
+
+func (t *tui) postLines(width, height int) []string {
+	title := "Posts"
+	if t.unreadOnly {
+		title = "Unread posts"
+	}
+	lines := []string{header(fmt.Sprintf("%s (%d)", title, len(t.posts)), width, t.focus == panePosts)}
+	if len(t.posts) == 0 {
+		return append(lines, fit(" No posts", width))
+	}
+
+	t.postTop = scrollTo(t.postIdx, t.postTop, height-1)
+	for i := t.postTop; i < len(t.posts) && len(lines) < height; i++ {
+		post := t.posts[i]
+		marker := "  "
+		if t.unread[post.ID] {
+			marker = " •"
+		}
+		star := " "
+		if t.starred[post.ID] {
+			star = "★"
+		}
+		line := fmt.Sprintf("%s%s %s  %s", marker, star, post.PublishedAt.Format("2006-01-02"), post.Title)
+		lines = append(lines, selectable(line, width, i == t.postIdx, t.focus == panePosts))
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func (t *tui) readerLines(width, height int) []string {
+	lines := []string{header("Reader", width, t.focus == paneReader)}
+	post, ok := t.selectedPost()
+	if !ok {
+		return lines
+	}
+
+	text := "No description"
+	if post.Content.Valid {
+		text = post.Content.String
+	} else if post.Description.Valid {
+		text = post.Description.String
+	}
+
+	var body []string
+	body = append(body, htmltext.Wrap(post.Title, width-2)...)
+	body = append(body, post.Url, post.PublishedAt.Format("Mon, 02 Jan 2006 15:04"), "")
+	body = append(body, strings.Split(htmltext.Render(text, width-2), "\n")...)
+
+	t.readerTop = clamp(t.readerTop, 0, max(len(body)-(height-1), 0))
+	for i := t.readerTop; i < len(body) && len(lines) < height; i++ {
+		lines = append(lines, fit(" "+body[i], width))
+	}
+	return lines
+}
+
+// header returns the title line of a pane, highlighted when the pane has focus
+func header(title string, width int, focused bool) string {
+	if focused {
+		return "\x1b[1;4m" + fit(" "+title, width) + "\x1b[0m"
+	}
+	return "\x1b[1m" + fit(" "+title, width) + "\x1b[0m"
+}
+
+// selectable returns a line of a list, highlighted when it's under the cursor
+func selectable(line string, width int, selected, focused bool) string {
+	line = fit(line, width)
+	switch {
+	case selected && focused:
+		return "\x1b[7m" + line + "\x1b[0m"
+	case selected:
+		return "\x1b[1m" + line + "\x1b[0m"
+	default:
+		return line
+	}
+}
+
+// lineAt returns the i-th line padded to the width of its pane, or a blank line if there isn't one
+func lineAt(lines []string, i, width int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return strings.Repeat(" ", width)
+}
+
+// scrollTo returns the new index of the first visible item of a list so that the selected item is
+// on screen
+func scrollTo(selected, top, height int) int {
+	if selected < top {
+		return selected
+	}
+	if selected >= top+height {
+		return selected - height + 1
+	}
+	return top
+}
+
+// fit truncates or pads a single line of text to exactly the given width
+func fit(s string, width int) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, s)
+
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		if width < 1 {
+			return ""
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+func clamp(n, low, high int) int {
+	return max(low, min(n, high))
+}
+
+// parseKey converts the bytes read from the terminal for a single key press to the name of the key
+func parseKey(b []byte) string {
+	switch string(b) {
+	case "\x1b[A", "\x1bOA":
+		return "up"
+	case "\x1b[B", "\x1bOB":
+		return "down"
+	case "\x1b[C", "\x1bOC":
+		return "right"
+	case "\x1b[D", "\x1bOD":
+		return "left"
+	case "\x1b[5~":
+		return "pgup"
+	case "\x1b[6~":
+		return "pgdown"
+	case "\x1b[Z":
+		return "shift+tab"
+	case "\r", "\n":
+		return "enter"
+	case "\t":
+		return "tab"
+	case "\x03":
+		return "ctrl+c"
+	default:
+		return string(b)
+	}
+}
+
+// enableRawMode puts the terminal into raw mode so that key presses are read one at a time
+// without being echoed. The returned function restores the previous mode. `stty` is used since
+// the standard library doesn't provide a way of changing the terminal mode.
+func enableRawMode() (func(), error) {
+	previous, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("Error reading terminal mode. Make sure that gator is running in a terminal: %w", err)
+	}
+
+	_, err = stty("raw", "-echo")
+	if err != nil {
+		return nil, fmt.Errorf("Error enabling raw mode: %w", err)
+	}
+
+	return func() {
+		stty(strings.TrimSpace(previous))
+	}, nil
+}
+
+// terminalSize returns the number of rows and columns of the terminal
+func terminalSize() (int, int, error) {
+	size, err := stty("size")
+	if err != nil {
+		return 0, 0, fmt.Errorf("Error reading terminal size: %w", err)
+	}
+
+	var rows, cols int
+	_, err = fmt.Sscan(size, &rows, &cols)
+	if err != nil || rows < 4 || cols < 20 {
+		return 24, 80, nil
+	}
+	return rows, cols, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}